@@ -0,0 +1,248 @@
+package gofabric
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Authenticator authenticates the requests sent by the Client.
+type Authenticator interface {
+	// Authenticate sets the credentials of the request, usually in a header. It is called for every
+	// attempt of every request, so that credentials can be rotated. A request is not sent, nor
+	// retried, if it returns an error.
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as an Authenticator.
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate calls f(req).
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// WithAuthenticator sets the authenticator of the requests, replacing the API key set by WithAPIKey.
+func WithAuthenticator(authenticator Authenticator) Option {
+	return func(c *Client) {
+		c.authenticator = authenticator
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the connections to the Fabric API server, e.g. to
+// present a client certificate for mutual TLS. The transport of the HTTP client, which is copied,
+// must be nil or an *http.Transport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// APIKey returns an Authenticator setting the X-API-Key header to key. An empty key sets no header.
+func APIKey(key string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		if key != "" {
+			req.Header.Set(apiKeyHeaderName, key)
+		}
+
+		return nil
+	})
+}
+
+// APIKeyFromFile returns an Authenticator setting the X-API-Key header to the content of the file at
+// path, without leading and trailing white space. The file is read again when its modification time
+// or size changes, so that the key can be rotated without recreating the Client.
+func APIKeyFromFile(path string) Authenticator {
+	return &fileAPIKey{path: path}
+}
+
+type fileAPIKey struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileAPIKey) Authenticate(req *http.Request) error {
+	key, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(apiKeyHeaderName, key)
+
+	return nil
+}
+
+// read returns the key, reading the file again if it has changed since it was last read.
+func (f *fileAPIKey) read() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key: %w", err)
+	}
+
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key: %w", err)
+	}
+
+	key := string(bytes.TrimSpace(data))
+	if key == "" {
+		return "", fmt.Errorf("failed to read API key: %s is empty", f.path)
+	}
+
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+
+	return key, nil
+}
+
+// APIKeyHeader returns an Authenticator moving the X-API-Key header set by authenticator to the
+// specified header, for proxies expecting the API key in another header.
+func APIKeyHeader(header string, authenticator Authenticator) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		if err := authenticator.Authenticate(req); err != nil {
+			return err
+		}
+
+		if key := req.Header.Get(apiKeyHeaderName); key != "" {
+			req.Header.Del(apiKeyHeaderName)
+			req.Header.Set(header, key)
+		}
+
+		return nil
+	})
+}
+
+// Token is a bearer token.
+type Token struct {
+	Value  string    // Value is the token sent in the Authorization header.
+	Expiry time.Time // Expiry is the time the token expires, zero if it does not expire.
+}
+
+// TokenSource returns the bearer tokens sent by BearerToken.
+type TokenSource interface {
+	// Token returns a valid token.
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// BearerToken returns an Authenticator setting the Authorization header to a bearer token obtained
+// from source for every request. Wrap source with RefreshingTokenSource to reuse a token until it
+// expires.
+func BearerToken(source TokenSource) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		token, err := source.Token(req.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get bearer token: %w", err)
+		}
+
+		if token == nil {
+			return errors.New("failed to get bearer token: nil token")
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token.Value)
+
+		return nil
+	})
+}
+
+// RefreshingTokenSource returns a TokenSource reusing the token returned by source until it is
+// about to expire, that is until earlyExpiry before its expiry, and getting a new one from source
+// then. It is safe for concurrent use; concurrent callers wait for a single refresh.
+func RefreshingTokenSource(source TokenSource, earlyExpiry time.Duration) TokenSource {
+	return &refreshingTokenSource{source: source, earlyExpiry: earlyExpiry}
+}
+
+type refreshingTokenSource struct {
+	source      TokenSource
+	earlyExpiry time.Duration
+
+	mu    sync.Mutex
+	token *Token
+}
+
+func (s *refreshingTokenSource) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil && (s.token.Expiry.IsZero() || time.Until(s.token.Expiry) > s.earlyExpiry) {
+		return s.token, nil
+	}
+
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if token == nil || token.Value == "" {
+		return nil, errors.New("empty token")
+	}
+
+	s.token = token
+
+	return token, nil
+}
+
+// authenticationError is returned by doAttempt when the Authenticator fails. It is not retried.
+type authenticationError struct {
+	err error
+}
+
+func (e *authenticationError) Error() string {
+	return "failed to authenticate request: " + e.err.Error()
+}
+
+func (e *authenticationError) Unwrap() error {
+	return e.err
+}
+
+// tlsHTTPClient returns a copy of httpClient using the TLS configuration. The transport of
+// httpClient must be nil or an *http.Transport.
+func tlsHTTPClient(httpClient *http.Client, config *tls.Config) (*http.Client, error) {
+	transport, err := cloneTransport(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set the TLS configuration: %w", err)
+	}
+
+	transport.TLSClientConfig = config
+
+	client := *httpClient
+	client.Transport = transport
+
+	return &client, nil
+}
+
+// cloneTransport returns a copy of the transport of httpClient, which must be nil or an
+// *http.Transport.
+func cloneTransport(httpClient *http.Client) (*http.Transport, error) {
+	switch t := httpClient.Transport.(type) {
+	case nil:
+		return http.DefaultTransport.(*http.Transport).Clone(), nil
+	case *http.Transport:
+		return t.Clone(), nil
+	default:
+		return nil, fmt.Errorf("unsupported transport of type %T", t)
+	}
+}
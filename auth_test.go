@@ -0,0 +1,223 @@
+package gofabric_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+// headerRecorder is a middleware recording a header of the requests going through it.
+type headerRecorder struct {
+	name   string
+	values []string
+}
+
+func (r *headerRecorder) middleware(next gofabric.Doer) gofabric.Doer {
+	return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+		r.values = append(r.values, req.HTTP.Header.Get(r.name))
+
+		return next.Do(req)
+	})
+}
+
+func TestAPIKeyHeader(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	recorder := headerRecorder{name: "X-Gateway-Key"}
+	client := server.Client(
+		gofabric.WithAuthenticator(gofabric.APIKeyHeader("X-Gateway-Key", gofabric.APIKey("secret"))),
+		gofabric.WithMiddleware(recorder.middleware, func(next gofabric.Doer) gofabric.Doer {
+			return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+				if key := req.HTTP.Header.Get("X-API-Key"); key != "" {
+					t.Errorf("Expected the X-API-Key header to be moved, got %q", key)
+				}
+
+				return next.Do(req)
+			})
+		}),
+	)
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"secret"}, recorder.values); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBearerTokenRefresh(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var calls atomic.Int32
+	source := gofabric.RefreshingTokenSource(
+		gofabric.TokenSourceFunc(func(context.Context) (*gofabric.Token, error) {
+			n := calls.Add(1)
+
+			// The first token is already within the early expiry window, the second one is not.
+			expiry := time.Now().Add(30 * time.Second)
+			if n > 1 {
+				expiry = time.Now().Add(time.Hour)
+			}
+
+			return &gofabric.Token{Value: fmt.Sprintf("token-%d", n), Expiry: expiry}, nil
+		}),
+		time.Minute,
+	)
+
+	recorder := headerRecorder{name: "Authorization"}
+	client := server.Client(
+		gofabric.WithAuthenticator(gofabric.BearerToken(source)),
+		gofabric.WithMiddleware(recorder.middleware),
+	)
+
+	for range 3 {
+		if _, err := client.ListPatterns(context.Background()); err != nil {
+			t.Fatalf("Failed to list patterns: %v", err)
+		}
+	}
+
+	want := []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"}
+	if diff := cmp.Diff(want, recorder.values); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBearerTokenNil(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var recorder requestRecorder
+	client := server.Client(
+		gofabric.WithAuthenticator(gofabric.BearerToken(gofabric.TokenSourceFunc(
+			func(context.Context) (*gofabric.Token, error) { return nil, nil },
+		))),
+		gofabric.WithMiddleware(recorder.middleware),
+	)
+
+	_, err := client.ListPatterns(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "nil token") {
+		t.Fatalf("Expected the nil token to be reported, got %v", err)
+	}
+
+	if got := recorder.operations(); len(got) != 0 {
+		t.Fatalf("Expected no request to be sent, got %v", got)
+	}
+}
+
+func TestAPIKeyFromFile(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer(gofabrictest.WithAPIKey("first"))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	recorder := headerRecorder{name: "X-API-Key"}
+	client := server.Client(
+		gofabric.WithAuthenticator(gofabric.APIKeyFromFile(path)),
+		gofabric.WithMiddleware(recorder.middleware),
+	)
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	// Rotate the key, making sure the modification time changes.
+	if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to change the modification time: %v", err)
+	}
+
+	if _, err := client.ListPatterns(context.Background()); !errors.Is(err, gofabric.ErrUnauthorized) {
+		t.Fatalf("Expected the rotated key to be rejected by the server, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second"}, recorder.values); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuthenticatorErrorNotRetried(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var recorder requestRecorder
+	client := server.Client(
+		gofabric.WithAuthenticator(gofabric.APIKeyFromFile(filepath.Join(t.TempDir(), "missing"))),
+		gofabric.WithRetryPolicy(gofabric.ExponentialBackoff{InitialInterval: time.Millisecond}),
+		gofabric.WithMiddleware(recorder.middleware),
+	)
+
+	_, err := client.ListPatterns(context.Background())
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected the missing key file to be reported, got %v", err)
+	}
+
+	if got := recorder.operations(); len(got) != 0 {
+		t.Fatalf("Expected no request to be sent, got %v", got)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]string{"summarize"})
+	}))
+	defer server.Close()
+
+	// Without the certificate of the server, the connection fails.
+	if _, err := gofabric.NewClient(server.URL).ListPatterns(context.Background()); err == nil {
+		t.Fatal("Expected the certificate of the server to be rejected")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client, err := gofabric.New(server.URL, gofabric.WithTLSConfig(&tls.Config{RootCAs: roots}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	names, err := client.ListPatterns(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"summarize"}, names); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,545 @@
+package gofabric
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	archiveVersion      = 1
+	archiveManifestName = "manifest.json"
+	archiveConfigName   = "config.json"
+	defaultRenameSuffix = ".bak"
+)
+
+// maxArchiveSize is the maximum size of an archive read by Import, and of the files it holds once
+// decompressed.
+const maxArchiveSize = 256 << 20
+
+// configURLProviders are the providers whose config value is the URL of a local server rather than
+// an API key.
+var configURLProviders = []string{"lmstudio", "ollama"}
+
+// ArchiveFormat represents the format of a backup archive.
+type ArchiveFormat string
+
+const (
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+	ArchiveFormatZip   ArchiveFormat = "zip"
+)
+
+// ConflictPolicy represents how Import handles an entity that already exists on the server.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicySkip keeps the existing entity.
+	ConflictPolicySkip ConflictPolicy = "skip"
+	// ConflictPolicyOverwrite replaces the existing entity.
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+	// ConflictPolicyRename renames the existing entity with a suffix before restoring the archived one.
+	ConflictPolicyRename ConflictPolicy = "rename"
+)
+
+// ImportAction represents the action taken on an entity by Import.
+type ImportAction string
+
+const (
+	ImportActionCreate    ImportAction = "create"
+	ImportActionOverwrite ImportAction = "overwrite"
+	ImportActionRename    ImportAction = "rename"
+	ImportActionSkip      ImportAction = "skip"
+)
+
+// ArchiveManifest describes the content of a backup archive. It is stored in the manifest.json file
+// at the root of the archive.
+type ArchiveManifest struct {
+	Version   int       `json:"version"`   // Version is the version of the archive layout.
+	CreatedAt time.Time `json:"createdAt"` // CreatedAt is the time the archive was created.
+	Contexts  []string  `json:"contexts"`  // Contexts lists the names of the archived contexts.
+	Patterns  []string  `json:"patterns"`  // Patterns lists the names of the archived patterns.
+	Sessions  []string  `json:"sessions"`  // Sessions lists the names of the archived sessions.
+	Redacted  bool      `json:"redacted"`  // Redacted reports whether the config API keys were redacted.
+}
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	Format        ArchiveFormat // Format is the format of the archive, ArchiveFormatTarGz if empty.
+	RedactAPIKeys bool          // RedactAPIKeys blanks the config API keys, keeping the other values.
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	Conflict      ConflictPolicy // Conflict is the policy for existing entities, ConflictPolicySkip if empty.
+	RenameSuffix  string         // RenameSuffix is the suffix used by ConflictPolicyRename, ".bak" if empty.
+	RestoreConfig bool           // RestoreConfig restores the archived config with UpdateConfig.
+}
+
+// ImportResult reports the action taken on a single entity by Import.
+type ImportResult struct {
+	EntityType EntityType   // EntityType is the type of the entity.
+	Name       string       // Name is the name of the entity.
+	Action     ImportAction // Action is the action taken on the entity.
+	RenamedTo  string       // RenamedTo is the new name of the existing entity for ImportActionRename.
+	Err        error        // Err is the error restoring the entity, nil on success.
+}
+
+// ImportReport holds the manifest of an imported archive and the results of the import, in archive
+// order: contexts, then patterns, then sessions.
+type ImportReport struct {
+	Manifest ArchiveManifest // Manifest is the manifest of the archive.
+	Results  []ImportResult  // Results holds the result for every entity.
+}
+
+// Err returns the errors of the failed imports joined with errors.Join, or nil if none failed.
+func (r *ImportReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Export writes a backup archive of every context, pattern and session of the server, and of its
+// config, to w.
+//
+// The archive holds a manifest.json file describing it, a config.json file, and a file per entity:
+// contexts/<name> and patterns/<name> with the content of the entity, and sessions/<name>.json with
+// the messages of the session.
+func (c *Client) Export(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	archive, err := newArchiveWriter(w, opts.Format)
+	if err != nil {
+		return err
+	}
+
+	manifest := ArchiveManifest{
+		Version:   archiveVersion,
+		CreatedAt: time.Now().UTC(),
+		Redacted:  opts.RedactAPIKeys,
+	}
+
+	files := make(map[string][]byte)
+
+	if manifest.Contexts, err = exportEntities(c, ctx, EntityTypeContext, files, func(entity *Context) ([]byte, error) {
+		return []byte(entity.Content), nil
+	}); err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	if manifest.Patterns, err = exportEntities(c, ctx, EntityTypePattern, files, func(entity *Pattern) ([]byte, error) {
+		return []byte(entity.Pattern), nil
+	}); err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	if manifest.Sessions, err = exportEntities(c, ctx, EntityTypeSession, files, func(entity *Session) ([]byte, error) {
+		return json.Marshal(entity.Messages)
+	}); err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	config, err := c.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	if opts.RedactAPIKeys {
+		for _, field := range configFields {
+			if !slices.Contains(configURLProviders, field.name) {
+				config.Set(field.name, "")
+			}
+		}
+	}
+
+	if files[archiveConfigName], err = json.Marshal(config); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if err := archive.writeFile(archiveManifestName, manifestData); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	for _, name := range archiveFileNames(manifest) {
+		if err := archive.writeFile(name, files[name]); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return nil
+}
+
+// Import restores a backup archive written by Export, in either format, handling the entities that
+// already exist on the server according to opts.Conflict.
+//
+// The archived config is restored only if opts.RestoreConfig is set and it was not redacted, so that
+// the API keys set on the server are kept.
+//
+// Archives larger than 256 MiB, or holding more than 256 MiB of files once decompressed, are
+// rejected.
+//
+// The returned error reports a failure to read the archive or to restore the config; failures
+// restoring entities are reported per entity in the ImportReport, see ImportReport.Err.
+func (c *Client) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	files, err := readArchive(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	report := &ImportReport{}
+
+	if err := json.Unmarshal(files[archiveManifestName], &report.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to read archive manifest: %w", decodeError(err))
+	}
+
+	if report.Manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("failed to read archive: unsupported version %d", report.Manifest.Version)
+	}
+
+	for _, name := range archiveFileNames(report.Manifest) {
+		if _, ok := files[name]; !ok {
+			return nil, fmt.Errorf("failed to read archive: missing %s", name)
+		}
+	}
+
+	entities := []struct {
+		entityType EntityType
+		names      []string
+	}{
+		{EntityTypeContext, report.Manifest.Contexts},
+		{EntityTypePattern, report.Manifest.Patterns},
+		{EntityTypeSession, report.Manifest.Sessions},
+	}
+
+	for _, entities := range entities {
+		for _, name := range entities.names {
+			data := files[archiveEntityFileName(entities.entityType, name)]
+			report.Results = append(report.Results, c.importEntity(ctx, entities.entityType, name, data, opts))
+		}
+	}
+
+	if opts.RestoreConfig && !report.Manifest.Redacted {
+		if err := c.importConfig(ctx, files[archiveConfigName]); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (c *Client) importEntity(
+	ctx context.Context,
+	entityType EntityType,
+	name string,
+	data []byte,
+	opts ImportOptions,
+) ImportResult {
+	result := ImportResult{EntityType: entityType, Name: name, Action: ImportActionCreate}
+
+	exists, err := entityExists(c, ctx, entityType, name)
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+
+	if exists {
+		switch opts.Conflict {
+		case ConflictPolicySkip, "":
+			result.Action = ImportActionSkip
+
+			return result
+		case ConflictPolicyOverwrite:
+			result.Action = ImportActionOverwrite
+		case ConflictPolicyRename:
+			result.Action = ImportActionRename
+
+			if result.RenamedTo, err = c.freeEntityName(ctx, entityType, name, opts.RenameSuffix); err != nil {
+				result.Err = err
+
+				return result
+			}
+
+			if err := renameEntity(c, ctx, entityType, name, result.RenamedTo); err != nil {
+				result.Err = err
+
+				return result
+			}
+		default:
+			result.Err = fmt.Errorf("failed to import %s `%s`: unknown conflict policy %q", entityType, name, opts.Conflict)
+
+			return result
+		}
+	}
+
+	result.Err = createEntity(c, ctx, entityType, name, bytes.NewReader(data))
+
+	return result
+}
+
+// freeEntityName returns the name with the suffix appended, followed by a number if an entity
+// already has that name.
+func (c *Client) freeEntityName(ctx context.Context, entityType EntityType, name string, suffix string) (string, error) {
+	if suffix == "" {
+		suffix = defaultRenameSuffix
+	}
+
+	for i := 1; ; i++ {
+		candidate := name + suffix
+		if i > 1 {
+			candidate += strconv.Itoa(i)
+		}
+
+		exists, err := entityExists(c, ctx, entityType, candidate)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists {
+			return candidate, nil
+		}
+	}
+}
+
+func (c *Client) importConfig(ctx context.Context, data []byte) error {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to read archive config: %w", decodeError(err))
+	}
+
+	if err := c.UpdateConfig(ctx, &config); err != nil {
+		return fmt.Errorf("failed to restore config: %w", err)
+	}
+
+	return nil
+}
+
+// exportEntities fetches every entity of the type, stores its encoded content in files and returns
+// the entity names.
+func exportEntities[T Entity](
+	client *Client,
+	ctx context.Context,
+	entityType EntityType,
+	files map[string][]byte,
+	encode func(entity *T) ([]byte, error),
+) ([]string, error) {
+	names, err := listEntity(client, ctx, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		entity, err := getEntity[T](client, ctx, entityType, name)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := encode(entity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s `%s`: %w", entityType, name, err)
+		}
+
+		files[archiveEntityFileName(entityType, name)] = data
+	}
+
+	return names, nil
+}
+
+// archiveFileNames returns the names of the files of the archive described by the manifest, other
+// than the manifest itself.
+func archiveFileNames(manifest ArchiveManifest) []string {
+	var names []string
+
+	for _, name := range manifest.Contexts {
+		names = append(names, archiveEntityFileName(EntityTypeContext, name))
+	}
+	for _, name := range manifest.Patterns {
+		names = append(names, archiveEntityFileName(EntityTypePattern, name))
+	}
+	for _, name := range manifest.Sessions {
+		names = append(names, archiveEntityFileName(EntityTypeSession, name))
+	}
+
+	return append(names, archiveConfigName)
+}
+
+func archiveEntityFileName(entityType EntityType, name string) string {
+	fileName := path.Join(string(entityType)+"s", name)
+	if entityType == EntityTypeSession {
+		fileName += ".json"
+	}
+
+	return fileName
+}
+
+// archiveWriter writes the files of an archive.
+type archiveWriter interface {
+	writeFile(name string, data []byte) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case ArchiveFormatTarGz, "":
+		gzipWriter := gzip.NewWriter(w)
+
+		return &tarGzWriter{gzipWriter: gzipWriter, tarWriter: tar.NewWriter(gzipWriter)}, nil
+	case ArchiveFormatZip:
+		return &zipWriter{zipWriter: zip.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("failed to export: unknown archive format %q", format)
+	}
+}
+
+type tarGzWriter struct {
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+}
+
+func (w *tarGzWriter) writeFile(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+
+	if err := w.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err := w.tarWriter.Write(data)
+
+	return err
+}
+
+func (w *tarGzWriter) Close() error {
+	return errors.Join(w.tarWriter.Close(), w.gzipWriter.Close())
+}
+
+type zipWriter struct {
+	zipWriter *zip.Writer
+}
+
+func (w *zipWriter) writeFile(name string, data []byte) error {
+	fileWriter, err := w.zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = fileWriter.Write(data)
+
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zipWriter.Close()
+}
+
+// readArchive returns the content of the files of a tar.gz or zip archive, keyed by name. The format
+// is detected from the content. Neither the archive nor its files may exceed maxArchiveSize.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	data, err := readArchiveFile(r, maxArchiveSize)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	remaining := int64(maxArchiveSize)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range zipReader.File {
+			if strings.HasSuffix(file.Name, "/") {
+				continue
+			}
+
+			fileReader, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := readArchiveFile(fileReader, remaining)
+			_ = fileReader.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			files[file.Name] = content
+			remaining -= int64(len(content))
+		}
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+
+			content, err := readArchiveFile(tarReader, remaining)
+			if err != nil {
+				return nil, err
+			}
+
+			files[header.Name] = content
+			remaining -= int64(len(content))
+		}
+	default:
+		return nil, errors.New("unknown archive format")
+	}
+
+	return files, nil
+}
+
+// readArchiveFile reads r to the end, failing if it holds more than limit bytes.
+func readArchiveFile(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("archive exceeds %d bytes", int64(maxArchiveSize))
+	}
+
+	return data, nil
+}
@@ -0,0 +1,258 @@
+package gofabric_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+var testBackupConfig = gofabric.Config{Anthropic: "anthropic-key", OpenAI: "openai-key"}
+
+func newBackupTestServer(t *testing.T) *gofabrictest.Server {
+	t.Helper()
+
+	server := gofabrictest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.SetContext(gofabric.Context{Name: "project.md", Content: "A Go client for Fabric"})
+	server.SetPattern(gofabric.Pattern{Name: "summarize", Pattern: "Summarize {{input}}"})
+	server.SetSession(gofabric.Session{
+		Name: "review",
+		Messages: []gofabric.Message{
+			gofabric.UserMessage("Hello"),
+			gofabric.AssistantMessage("Hi"),
+		},
+	})
+	server.SetConfig(testBackupConfig)
+
+	return server
+}
+
+func exportTestServer(t *testing.T, server *gofabrictest.Server, opts gofabric.ExportOptions) []byte {
+	t.Helper()
+
+	var archive bytes.Buffer
+	if err := server.Client().Export(context.Background(), &archive, opts); err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	return archive.Bytes()
+}
+
+func TestExportImport(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []gofabric.ArchiveFormat{gofabric.ArchiveFormatTarGz, gofabric.ArchiveFormatZip} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			source := newBackupTestServer(t)
+			archive := exportTestServer(t, source, gofabric.ExportOptions{Format: format})
+
+			target := gofabrictest.NewServer()
+			defer target.Close()
+
+			report, err := target.Client().Import(
+				context.Background(),
+				bytes.NewReader(archive),
+				gofabric.ImportOptions{RestoreConfig: true},
+			)
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+
+			if err := report.Err(); err != nil {
+				t.Fatalf("Failed to import entities: %v", err)
+			}
+
+			if diff := cmp.Diff(1, report.Manifest.Version); diff != "" {
+				t.Fatalf("Version mismatch (-want +got):\n%s", diff)
+			}
+
+			wantResults := []gofabric.ImportResult{
+				{EntityType: gofabric.EntityTypeContext, Name: "project.md", Action: gofabric.ImportActionCreate},
+				{EntityType: gofabric.EntityTypePattern, Name: "summarize", Action: gofabric.ImportActionCreate},
+				{EntityType: gofabric.EntityTypeSession, Name: "review", Action: gofabric.ImportActionCreate},
+			}
+			if diff := cmp.Diff(wantResults, report.Results); diff != "" {
+				t.Fatalf("Results mismatch (-want +got):\n%s", diff)
+			}
+
+			wantContext, _ := source.Context("project.md")
+			gotContext, _ := target.Context("project.md")
+			if diff := cmp.Diff(wantContext, gotContext); diff != "" {
+				t.Fatalf("Context mismatch (-want +got):\n%s", diff)
+			}
+
+			wantPattern, _ := source.Pattern("summarize")
+			gotPattern, _ := target.Pattern("summarize")
+			if diff := cmp.Diff(wantPattern, gotPattern); diff != "" {
+				t.Fatalf("Pattern mismatch (-want +got):\n%s", diff)
+			}
+
+			wantSession, _ := source.Session("review")
+			gotSession, _ := target.Session("review")
+			if diff := cmp.Diff(wantSession, gotSession); diff != "" {
+				t.Fatalf("Session mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(testBackupConfig, target.Config()); diff != "" {
+				t.Fatalf("Config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExportRedactsAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	server := newBackupTestServer(t)
+
+	config := testBackupConfig
+	config.Ollama = "http://localhost:11434"
+	config.Set("ollamaTimeout", "30s")
+	server.SetConfig(config)
+
+	archive := exportTestServer(t, server, gofabric.ExportOptions{
+		Format:        gofabric.ArchiveFormatZip,
+		RedactAPIKeys: true,
+	})
+
+	zipReader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range zipReader.File {
+		fileReader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+
+		files[file.Name], err = io.ReadAll(fileReader)
+		_ = fileReader.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+	}
+
+	var manifest gofabric.ArchiveManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+
+	if !manifest.Redacted {
+		t.Fatal("Expected the manifest to be marked as redacted")
+	}
+
+	var archivedConfig gofabric.Config
+	if err := json.Unmarshal(files["config.json"], &archivedConfig); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+
+	// Only the API keys are redacted.
+	want := map[string]string{"ollama": "http://localhost:11434", "ollamaTimeout": "30s"}
+	got := archivedConfig.Providers()
+	maps.DeleteFunc(got, func(_ string, value string) bool { return value == "" })
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Config mismatch (-want +got):\n%s", diff)
+	}
+
+	target := gofabrictest.NewServer()
+	defer target.Close()
+
+	target.SetConfig(gofabric.Config{OpenAI: "target-key"})
+
+	if _, err := target.Client().Import(
+		context.Background(),
+		bytes.NewReader(archive),
+		gofabric.ImportOptions{RestoreConfig: true},
+	); err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+
+	if diff := cmp.Diff(gofabric.Config{OpenAI: "target-key"}, target.Config()); diff != "" {
+		t.Fatalf("Expected the config to be kept (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	t.Parallel()
+
+	archive := exportTestServer(t, newBackupTestServer(t), gofabric.ExportOptions{})
+
+	tests := []struct {
+		name        string
+		opts        gofabric.ImportOptions
+		wantResult  gofabric.ImportResult
+		wantPattern map[string]string
+	}{
+		{
+			name: "skip",
+			opts: gofabric.ImportOptions{},
+			wantResult: gofabric.ImportResult{
+				EntityType: gofabric.EntityTypePattern,
+				Name:       "summarize",
+				Action:     gofabric.ImportActionSkip,
+			},
+			wantPattern: map[string]string{"summarize": "Local", "summarize.bak": ""},
+		},
+		{
+			name: "overwrite",
+			opts: gofabric.ImportOptions{Conflict: gofabric.ConflictPolicyOverwrite},
+			wantResult: gofabric.ImportResult{
+				EntityType: gofabric.EntityTypePattern,
+				Name:       "summarize",
+				Action:     gofabric.ImportActionOverwrite,
+			},
+			wantPattern: map[string]string{"summarize": "Summarize {{input}}", "summarize.bak": ""},
+		},
+		{
+			name: "rename",
+			opts: gofabric.ImportOptions{Conflict: gofabric.ConflictPolicyRename},
+			wantResult: gofabric.ImportResult{
+				EntityType: gofabric.EntityTypePattern,
+				Name:       "summarize",
+				Action:     gofabric.ImportActionRename,
+				RenamedTo:  "summarize.bak",
+			},
+			wantPattern: map[string]string{"summarize": "Summarize {{input}}", "summarize.bak": "Local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := gofabrictest.NewServer()
+			defer server.Close()
+
+			server.SetPattern(gofabric.Pattern{Name: "summarize", Pattern: "Local"})
+
+			report, err := server.Client().Import(context.Background(), bytes.NewReader(archive), tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to import: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantResult, report.Results[1]); diff != "" {
+				t.Fatalf("Result mismatch (-want +got):\n%s", diff)
+			}
+
+			for name, want := range tt.wantPattern {
+				pattern, _ := server.Pattern(name)
+				if diff := cmp.Diff(want, pattern.Pattern); diff != "" {
+					t.Fatalf("Pattern %q mismatch (-want +got):\n%s", name, diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package gofabric
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultBatchConcurrency = 4
+
+// BatchOptions configures BatchChat.
+type BatchOptions struct {
+	Concurrency int // Concurrency is the maximum number of concurrent chats, 4 if not positive.
+
+	// RetryPolicy decides whether a failed chat is run again from the start, nil to run every chat
+	// once. It is called with the response of the failure for HTTP errors, and with the error
+	// otherwise. Invalid chat requests and cancelled contexts are never retried.
+	//
+	// Unlike the policy set by WithRetryPolicy, which only replays requests the server has not
+	// started processing, it also retries chats whose stream failed or was interrupted.
+	RetryPolicy RetryPolicy
+
+	// CheckpointPath is the path of a JSON Lines file recording the result of every completed chat,
+	// empty for no checkpoint. When the file already exists, the chats it records are not run again,
+	// so that an interrupted batch can be resumed by calling BatchChat with the same requests.
+	// Records whose chat request differs from the one at the same index are ignored.
+	CheckpointPath string
+
+	// OnProgress is called after every chat of the batch ends, including the chats resumed from the
+	// checkpoint. Calls are serialized, so it does not need to be safe for concurrent use, but the
+	// batch waits for it to return.
+	OnProgress func(progress BatchProgress)
+}
+
+// BatchResult reports the outcome of a single chat of a batch.
+type BatchResult struct {
+	Index    int         // Index is the index of the chat request in the batch.
+	Result   *ChatResult // Result is the aggregated result of the chat, nil if it failed.
+	Err      error       // Err is the error of the last attempt, nil on success.
+	Attempts int         // Attempts is the number of attempts made, 0 if resumed from the checkpoint.
+	Resumed  bool        // Resumed reports whether the result was read from the checkpoint.
+}
+
+// BatchProgress reports the progress of a batch when a chat ends.
+type BatchProgress struct {
+	Total     int         // Total is the number of chats in the batch.
+	Completed int         // Completed is the number of chats that ended successfully so far.
+	Failed    int         // Failed is the number of chats that failed so far.
+	Result    BatchResult // Result is the result of the chat that ended.
+}
+
+// BatchReport holds the results of a batch, in the order of the chat requests.
+type BatchReport struct {
+	Results []BatchResult // Results holds the result for every chat request.
+}
+
+// Failed returns the results of the chats that failed.
+func (r *BatchReport) Failed() []BatchResult {
+	var failed []BatchResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Err returns the errors of the failed chats joined with errors.Join, or nil if none failed.
+func (r *BatchReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("chat request %d: %w", result.Index, result.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// BatchChat runs the chat requests with at most opts.Concurrency concurrent chats, aggregating the
+// stream of each one like ChatComplete, and waits for them to end. See BatchOptions for retrying
+// failed chats, checkpointing the progress of the batch and reporting it.
+//
+// The returned error reports a failure to read or write the checkpoint; failures of the chats are
+// reported per request in the BatchReport, see BatchReport.Err. When ctx is cancelled, the chats
+// that have not ended fail with the error of the context.
+func (c *Client) BatchChat(
+	ctx context.Context,
+	chatRequests []ChatRequest,
+	opts BatchOptions,
+) (*BatchReport, error) {
+	report := &BatchReport{Results: make([]BatchResult, len(chatRequests))}
+
+	hashes := make([]string, len(chatRequests))
+	for i := range chatRequests {
+		data, err := json.Marshal(&chatRequests[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode chat request %d: %w", i, err)
+		}
+
+		hashes[i] = contentHash(string(data))
+	}
+
+	var checkpoint *batchCheckpoint
+	if opts.CheckpointPath != "" {
+		var err error
+		if checkpoint, err = openBatchCheckpoint(opts.CheckpointPath); err != nil {
+			return nil, err
+		}
+		defer func() { _ = checkpoint.close() }()
+	}
+
+	progress := batchProgress{total: len(chatRequests), onProgress: opts.OnProgress}
+
+	var pending []int
+	for i := range chatRequests {
+		report.Results[i].Index = i
+
+		if result, ok := checkpoint.result(i, hashes[i]); ok {
+			report.Results[i].Result = result
+			report.Results[i].Resumed = true
+			progress.report(report.Results[i])
+
+			continue
+		}
+
+		pending = append(pending, i)
+	}
+
+	forEach(pending, opts.concurrency(), func(i int) {
+		result := &report.Results[i]
+		result.Result, result.Attempts, result.Err = c.batchChat(ctx, &chatRequests[i], opts.RetryPolicy)
+
+		if result.Err == nil {
+			if err := checkpoint.record(i, hashes[i], result.Result); err != nil {
+				result.Err = err
+			}
+		}
+
+		progress.report(*result)
+	})
+
+	if err := checkpoint.close(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// batchChat runs a chat of a batch until it succeeds or the retry policy gives up, and returns its
+// result along with the number of attempts made.
+func (c *Client) batchChat(
+	ctx context.Context,
+	chatRequest *ChatRequest,
+	policy RetryPolicy,
+) (*ChatResult, int, error) {
+	op := operation{name: "BatchChat", chatRequest: chatRequest}
+
+	for attempt := 1; ; attempt++ {
+		result, err := c.chatComplete(ctx, op)
+		if err == nil || policy == nil || !isBatchRetryable(ctx, err) {
+			return result, attempt, err
+		}
+
+		// As for the requests of the Client, the policy gets the response of HTTP errors.
+		resp, retryErr := (*http.Response)(nil), err
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.response != nil {
+			resp, retryErr = httpErr.response, nil
+		}
+
+		delay, ok := policy.Retry(attempt, resp, retryErr)
+		if !ok || !sleep(ctx, delay) {
+			return nil, attempt, err
+		}
+	}
+}
+
+// isBatchRetryable reports whether a failed chat of a batch may be run again: chat requests rejected
+// by the client-side validation would fail again, and a done context ends the batch.
+func isBatchRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for _, target := range []error{ErrInvalidRequest, ErrUnknownModel, ErrAmbiguousModel} {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+
+	var authErr *authenticationError
+
+	return !errors.As(err, &authErr)
+}
+
+func (o BatchOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultBatchConcurrency
+	}
+
+	return o.Concurrency
+}
+
+// batchProgress counts the chats of a batch that ended and reports them to the progress callback.
+type batchProgress struct {
+	total      int
+	onProgress func(progress BatchProgress)
+
+	mu        sync.Mutex
+	completed int
+	failed    int
+}
+
+func (p *batchProgress) report(result BatchResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result.Err == nil {
+		p.completed++
+	} else {
+		p.failed++
+	}
+
+	if p.onProgress != nil {
+		p.onProgress(BatchProgress{
+			Total:     p.total,
+			Completed: p.completed,
+			Failed:    p.failed,
+			Result:    result,
+		})
+	}
+}
+
+// batchCheckpointRecord is a line of a checkpoint file, recording a completed chat.
+type batchCheckpointRecord struct {
+	Index            int           `json:"index"`
+	RequestHash      string        `json:"requestHash"`
+	Content          string        `json:"content"`
+	Segments         []ChatSegment `json:"segments"`
+	TimeToFirstToken time.Duration `json:"timeToFirstToken"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// batchCheckpoint is an open checkpoint file. A nil *batchCheckpoint records nothing.
+type batchCheckpoint struct {
+	records map[int]batchCheckpointRecord
+
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// openBatchCheckpoint reads the records of the checkpoint file at path, creating it if needed, and
+// opens it for appending new records.
+func openBatchCheckpoint(path string) (*batchCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	checkpoint := &batchCheckpoint{records: make(map[int]batchCheckpointRecord), file: file}
+
+	if err := checkpoint.read(); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+// read reads the records of the checkpoint file. Lines that cannot be decoded, such as a last line
+// truncated by an interruption, are ignored, and a missing final newline is added so that new records
+// start on their own line.
+func (cp *batchCheckpoint) read() error {
+	reader := bufio.NewReader(cp.file)
+
+	var last string
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			last = line
+		}
+
+		var record batchCheckpointRecord
+		if line != "" && json.Unmarshal([]byte(line), &record) == nil && record.Index >= 0 {
+			cp.records[record.Index] = record
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if last != "" && last[len(last)-1] != '\n' {
+		if _, err := cp.file.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// result returns the result recorded for the chat request at index with the specified hash.
+func (cp *batchCheckpoint) result(index int, requestHash string) (*ChatResult, bool) {
+	if cp == nil {
+		return nil, false
+	}
+
+	record, ok := cp.records[index]
+	if !ok || record.RequestHash != requestHash {
+		return nil, false
+	}
+
+	return &ChatResult{
+		Content:          record.Content,
+		Segments:         record.Segments,
+		TimeToFirstToken: record.TimeToFirstToken,
+		Duration:         record.Duration,
+	}, true
+}
+
+// record appends the result of the chat request at index to the checkpoint file.
+func (cp *batchCheckpoint) record(index int, requestHash string, result *ChatResult) error {
+	if cp == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(batchCheckpointRecord{
+		Index:            index,
+		RequestHash:      requestHash,
+		Content:          result.Content,
+		Segments:         result.Segments,
+		TimeToFirstToken: result.TimeToFirstToken,
+		Duration:         result.Duration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if _, err := cp.file.Write(append(data, '\n')); err != nil {
+		cp.err = fmt.Errorf("failed to write checkpoint: %w", err)
+
+		return cp.err
+	}
+
+	return nil
+}
+
+// close closes the checkpoint file and returns the first error writing it. It can be called several
+// times.
+func (cp *batchCheckpoint) close() error {
+	if cp == nil {
+		return nil
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.file != nil {
+		if err := cp.file.Close(); err != nil && cp.err == nil {
+			cp.err = fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+
+		cp.file = nil
+	}
+
+	return cp.err
+}
@@ -0,0 +1,252 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func batchChatRequests(inputs ...string) []gofabric.ChatRequest {
+	chatRequests := make([]gofabric.ChatRequest, len(inputs))
+	for i, input := range inputs {
+		chatRequests[i] = gofabric.ChatRequest{Prompts: []gofabric.PromptRequest{{UserInput: input}}}
+	}
+
+	return chatRequests
+}
+
+func batchContents(report *gofabric.BatchReport) []string {
+	contents := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		if result.Result != nil {
+			contents[i] = result.Result.Content
+		}
+	}
+
+	return contents
+}
+
+// failingChatScript echoes the user input, except for the inputs in failures, which fail with a
+// stream error as long as their count is positive.
+func failingChatScript(failures map[string]int) gofabrictest.ChatScript {
+	var mu sync.Mutex
+
+	return func(chatRequest *gofabric.ChatRequest) []gofabric.StreamResponse {
+		mu.Lock()
+		defer mu.Unlock()
+
+		input := chatRequest.Prompts[0].UserInput
+		if failures[input] > 0 {
+			failures[input]--
+
+			return []gofabric.StreamResponse{
+				{Type: string(gofabric.StreamResponseTypeError), Content: "overloaded"},
+			}
+		}
+
+		return []gofabric.StreamResponse{
+			{Type: string(gofabric.StreamResponseTypeContent), Format: "markdown", Content: input},
+			{Type: string(gofabric.StreamResponseTypeComplete), Format: "plain"},
+		}
+	}
+}
+
+func TestBatchChat(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChunkDelay(time.Millisecond)
+
+	var inputs []string
+	for i := range 20 {
+		inputs = append(inputs, fmt.Sprintf("input-%d", i))
+	}
+
+	var progress []gofabric.BatchProgress
+	opts := gofabric.BatchOptions{
+		Concurrency: 5,
+		OnProgress: func(p gofabric.BatchProgress) {
+			progress = append(progress, p)
+		},
+	}
+
+	report, err := server.Client().BatchChat(context.Background(), batchChatRequests(inputs...), opts)
+	if err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Fatalf("Expected every chat to succeed, got %v", err)
+	}
+
+	if diff := cmp.Diff(inputs, batchContents(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(progress) != len(inputs) {
+		t.Fatalf("Expected %d progress reports, got %d", len(inputs), len(progress))
+	}
+
+	for i, p := range progress {
+		if p.Total != len(inputs) || p.Completed != i+1 || p.Failed != 0 {
+			t.Fatalf("Unexpected progress report %d: %+v", i, p)
+		}
+	}
+}
+
+func TestBatchChatRetry(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatScript(failingChatScript(map[string]int{"flaky": 2, "broken": 10}))
+
+	chatRequests := append(batchChatRequests("flaky", "broken", "ok"), gofabric.ChatRequest{})
+	report, err := server.Client().BatchChat(context.Background(), chatRequests, gofabric.BatchOptions{
+		RetryPolicy: gofabric.ExponentialBackoff{MaxAttempts: 3, InitialInterval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+
+	attempts := make([]int, len(report.Results))
+	for i, result := range report.Results {
+		attempts[i] = result.Attempts
+	}
+
+	// The invalid chat request is not retried.
+	if diff := cmp.Diff([]int{3, 3, 1, 1}, attempts); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"flaky", "", "ok", ""}, batchContents(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	var streamErr *gofabric.StreamError
+	if !errors.As(report.Results[1].Err, &streamErr) {
+		t.Fatalf("Expected a stream error, got %v", report.Results[1].Err)
+	}
+
+	if !errors.Is(report.Results[3].Err, gofabric.ErrInvalidRequest) {
+		t.Fatalf("Expected an invalid request error, got %v", report.Results[3].Err)
+	}
+
+	if got := len(report.Failed()); got != 2 {
+		t.Fatalf("Expected 2 failed chats, got %d", got)
+	}
+}
+
+func TestBatchChatCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatScript(failingChatScript(map[string]int{"second": 1}))
+
+	path := filepath.Join(t.TempDir(), "checkpoint.jsonl")
+	opts := gofabric.BatchOptions{CheckpointPath: path}
+	chatRequests := batchChatRequests("first", "second", "third")
+
+	report, err := server.Client().BatchChat(context.Background(), chatRequests, opts)
+	if err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+
+	if got := len(report.Failed()); got != 1 {
+		t.Fatalf("Expected 1 failed chat, got %d", got)
+	}
+
+	// Simulate a record truncated by an interruption.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+
+	if _, err := file.WriteString(`{"index":1,"requestHa`); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close checkpoint: %v", err)
+	}
+
+	// The changed chat request is run again along with the failed one.
+	chatRequests[2] = batchChatRequests("changed")[0]
+
+	report, err = server.Client().BatchChat(context.Background(), chatRequests, opts)
+	if err != nil {
+		t.Fatalf("Failed to resume batch: %v", err)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Fatalf("Expected every chat to succeed, got %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second", "changed"}, batchContents(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	resumed := make([]bool, len(report.Results))
+	for i, result := range report.Results {
+		resumed[i] = result.Resumed
+	}
+
+	if diff := cmp.Diff([]bool{true, false, false}, resumed); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	// Every chat request is now recorded, so nothing is sent.
+	sent := len(server.ChatRequests())
+
+	report, err = server.Client().BatchChat(context.Background(), chatRequests, opts)
+	if err != nil {
+		t.Fatalf("Failed to resume batch: %v", err)
+	}
+
+	if got := len(server.ChatRequests()); got != sent {
+		t.Fatalf("Expected no chat request to be sent, got %d", got-sent)
+	}
+
+	if diff := cmp.Diff([]string{"first", "second", "changed"}, batchContents(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBatchChatCancelled(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts := gofabric.BatchOptions{
+		RetryPolicy: gofabric.ExponentialBackoff{InitialInterval: time.Millisecond},
+	}
+
+	report, err := server.Client().BatchChat(ctx, batchChatRequests("first", "second"), opts)
+	if err != nil {
+		t.Fatalf("Failed to run batch: %v", err)
+	}
+
+	for _, result := range report.Results {
+		if !errors.Is(result.Err, context.Canceled) || result.Attempts != 1 {
+			t.Fatalf("Expected the chat to fail once with the context error, got %+v", result)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package gofabric
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxModelSuggestions = 3
+
+// VendorModel identifies a model offered by a vendor.
+type VendorModel struct {
+	Vendor string // Vendor is the name of the LLM vendor.
+	Model  string // Model is the name of the model.
+}
+
+// String returns the vendor and model separated by a slash.
+func (vm VendorModel) String() string {
+	return vm.Vendor + "/" + vm.Model
+}
+
+// ModelCatalog validates vendor/model pairs against the models available on the Fabric server, as
+// reported by ListModels. Vendor names are matched case-insensitively, model names exactly.
+//
+// The list of models is fetched on first use and cached. It is fetched again in the background on use
+// once the refresh interval has elapsed, the stale list being used until the fetch succeeds; a
+// refresh interval of 0 caches it until Refresh is called. Concurrent uses share a single fetch.
+//
+// A ModelCatalog is safe for concurrent use.
+type ModelCatalog struct {
+	client          *Client
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	models    []VendorModel
+	fetchedAt time.Time
+	fetch     *modelFetch // fetch is the fetch in progress, nil if none.
+}
+
+// modelFetch is a fetch of the list of available models shared by the callers waiting for it.
+type modelFetch struct {
+	done chan struct{} // done is closed when the fetch ends.
+	err  error         // err is the error of the fetch, set before done is closed.
+}
+
+// NewModelCatalog creates a ModelCatalog fetching the available models with client.
+func NewModelCatalog(client *Client, refreshInterval time.Duration) *ModelCatalog {
+	return &ModelCatalog{
+		client:          client,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// WithModelValidation makes the client validate the vendor/model pair of every prompt against a
+// ModelCatalog before sending a chat request, rejecting unknown models with an *UnknownModelError.
+// The catalog is refreshed at the specified interval and is available through Client.ModelCatalog.
+func WithModelValidation(refreshInterval time.Duration) Option {
+	return func(c *Client) {
+		c.modelCatalog = NewModelCatalog(c, refreshInterval)
+	}
+}
+
+// ModelCatalog returns the catalog used to validate chat requests, or nil if the client was not
+// created with WithModelValidation.
+func (c *Client) ModelCatalog() *ModelCatalog {
+	return c.modelCatalog
+}
+
+// Refresh fetches the list of available models, or waits for the fetch in progress. The fetch is
+// not cancelled with ctx, which only stops the wait.
+func (m *ModelCatalog) Refresh(ctx context.Context) error {
+	m.mu.Lock()
+	fetch := m.startFetch(ctx)
+	m.mu.Unlock()
+
+	return fetch.wait(ctx)
+}
+
+// startFetch starts fetching the list of available models, unless a fetch is already in progress,
+// and returns the fetch. m.mu must be held.
+//
+// The fetch is shared by several callers, so it does not stop when ctx is cancelled.
+func (m *ModelCatalog) startFetch(ctx context.Context) *modelFetch {
+	if m.fetch != nil {
+		return m.fetch
+	}
+
+	fetch := &modelFetch{done: make(chan struct{})}
+	m.fetch = fetch
+
+	go func() {
+		models, err := m.fetchModels(context.WithoutCancel(ctx))
+
+		m.mu.Lock()
+		if err == nil {
+			m.models = models
+			m.fetchedAt = time.Now()
+		}
+		fetch.err = err
+		m.fetch = nil
+		m.mu.Unlock()
+
+		close(fetch.done)
+	}()
+
+	return fetch
+}
+
+// wait waits for the fetch to end or ctx to be done, and returns the error of the fetch.
+func (f *modelFetch) wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetchModels fetches the list of available models, sorted by vendor and model.
+func (m *ModelCatalog) fetchModels(ctx context.Context) ([]VendorModel, error) {
+	availableModels, err := m.client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh model catalog: %w", err)
+	}
+
+	var models []VendorModel
+	for vendor, vendorModels := range availableModels.Vendors {
+		for _, model := range vendorModels {
+			models = append(models, VendorModel{Vendor: vendor, Model: model})
+		}
+	}
+
+	slices.SortFunc(models, func(a VendorModel, b VendorModel) int {
+		return cmp.Or(cmp.Compare(a.Vendor, b.Vendor), cmp.Compare(a.Model, b.Model))
+	})
+
+	return models, nil
+}
+
+// Models returns the available models, sorted by vendor and model.
+func (m *ModelCatalog) Models(ctx context.Context) ([]VendorModel, error) {
+	models, err := m.load(ctx)
+
+	return slices.Clone(models), err
+}
+
+// load returns the cached list of available models. If it is stale, it is returned while a refresh
+// runs in the background; if it is missing, load waits for it to be fetched or for ctx to be done.
+// The returned slice must not be modified.
+func (m *ModelCatalog) load(ctx context.Context) ([]VendorModel, error) {
+	m.mu.Lock()
+
+	if !m.fetchedAt.IsZero() {
+		if m.refreshInterval > 0 && time.Since(m.fetchedAt) >= m.refreshInterval {
+			m.startFetch(ctx)
+		}
+
+		models := m.models
+		m.mu.Unlock()
+
+		return models, nil
+	}
+
+	fetch := m.startFetch(ctx)
+	m.mu.Unlock()
+
+	if err := fetch.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.models, nil
+}
+
+// Validate checks that vendor offers model. It returns an *UnknownModelError with the closest
+// matches as suggestions if it does not.
+func (m *ModelCatalog) Validate(ctx context.Context, vendor string, model string) error {
+	models, err := m.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	wanted := VendorModel{Vendor: vendor, Model: model}
+	for _, candidate := range models {
+		if strings.EqualFold(candidate.Vendor, vendor) && candidate.Model == model {
+			return nil
+		}
+	}
+
+	return &UnknownModelError{VendorModel: wanted, Suggestions: suggestModels(models, wanted)}
+}
+
+// InferVendor returns the vendor offering model. It returns an *UnknownModelError if no vendor
+// offers it, and an error matching ErrAmbiguousModel if several vendors do.
+func (m *ModelCatalog) InferVendor(ctx context.Context, model string) (string, error) {
+	models, err := m.load(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var vendors []string
+	for _, candidate := range models {
+		if candidate.Model == model {
+			vendors = append(vendors, candidate.Vendor)
+		}
+	}
+
+	switch len(vendors) {
+	case 0:
+		wanted := VendorModel{Model: model}
+
+		return "", &UnknownModelError{VendorModel: wanted, Suggestions: suggestModels(models, wanted)}
+	case 1:
+		return vendors[0], nil
+	default:
+		return "", fmt.Errorf("%w: %q is offered by %s", ErrAmbiguousModel, model, strings.Join(vendors, ", "))
+	}
+}
+
+// Suggest returns up to three available models closest to the specified vendor and model, closest
+// first. The vendor may be empty to search every vendor.
+func (m *ModelCatalog) Suggest(ctx context.Context, vendor string, model string) ([]VendorModel, error) {
+	models, err := m.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return suggestModels(models, VendorModel{Vendor: vendor, Model: model}), nil
+}
+
+// validateChatRequest validates the vendor/model pair of every prompt of the chat request.
+func (m *ModelCatalog) validateChatRequest(ctx context.Context, chatRequest *ChatRequest) error {
+	for _, prompt := range chatRequest.Prompts {
+		if prompt.Vendor == "" && prompt.Model == "" {
+			continue
+		}
+
+		if err := m.Validate(ctx, prompt.Vendor, prompt.Model); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// suggestModels returns the models closest to wanted. Candidates are ranked by the edit distance
+// between the model names, with a penalty for a different vendor when wanted has one.
+func suggestModels(models []VendorModel, wanted VendorModel) []VendorModel {
+	type suggestion struct {
+		model    VendorModel
+		distance int
+	}
+
+	wantedModel := strings.ToLower(wanted.Model)
+	maxDistance := max(2, len(wantedModel)/5)
+
+	var suggestions []suggestion
+	for _, candidate := range models {
+		distance := levenshtein(wantedModel, strings.ToLower(candidate.Model))
+		if distance > maxDistance {
+			continue
+		}
+
+		if wanted.Vendor != "" && !strings.EqualFold(wanted.Vendor, candidate.Vendor) {
+			distance++
+		}
+
+		suggestions = append(suggestions, suggestion{model: candidate, distance: distance})
+	}
+
+	slices.SortStableFunc(suggestions, func(a suggestion, b suggestion) int {
+		return cmp.Compare(a.distance, b.distance)
+	})
+
+	var result []VendorModel
+	for _, s := range suggestions[:min(len(suggestions), maxModelSuggestions)] {
+		result = append(result, s.model)
+	}
+
+	return result
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
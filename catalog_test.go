@@ -0,0 +1,232 @@
+package gofabric_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+var testModels = gofabric.AvailableModels{
+	Models: []string{"gemini-2.0-flash", "gemini-2.5-pro", "gpt-4o", "gpt-4o-mini", "llama3"},
+	Vendors: map[string][]string{
+		"Gemini": {"gemini-2.0-flash", "gemini-2.5-pro"},
+		"OpenAI": {"gpt-4o", "gpt-4o-mini"},
+		"Ollama": {"llama3"},
+		"Groq":   {"llama3"},
+	},
+}
+
+func newTestCatalog(t *testing.T) *gofabric.ModelCatalog {
+	t.Helper()
+
+	server := gofabrictest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.SetModels(testModels)
+
+	return gofabric.NewModelCatalog(server.Client(), 0)
+}
+
+func TestModelCatalogValidate(t *testing.T) {
+	t.Parallel()
+
+	catalog := newTestCatalog(t)
+	ctx := context.Background()
+
+	if err := catalog.Validate(ctx, "gemini", "gemini-2.0-flash"); err != nil {
+		t.Fatalf("Expected valid model, got: %v", err)
+	}
+
+	err := catalog.Validate(ctx, "Gemini", "gemini-2.0-flsh")
+	if !errors.Is(err, gofabric.ErrUnknownModel) {
+		t.Fatalf("Expected ErrUnknownModel, got: %v", err)
+	}
+
+	want := `unknown model "gemini-2.0-flsh" for vendor "Gemini"; did you mean "Gemini/gemini-2.0-flash"?`
+	if diff := cmp.Diff(want, err.Error()); diff != "" {
+		t.Fatalf("Message mismatch (-want +got):\n%s", diff)
+	}
+
+	err = catalog.Validate(ctx, "OpenAI", "gemini-2.5-pro")
+	var unknownErr *gofabric.UnknownModelError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Expected *UnknownModelError, got: %v", err)
+	}
+
+	wantSuggestions := []gofabric.VendorModel{{Vendor: "Gemini", Model: "gemini-2.5-pro"}}
+	if diff := cmp.Diff(wantSuggestions, unknownErr.Suggestions); diff != "" {
+		t.Fatalf("Suggestions mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModelCatalogInferVendor(t *testing.T) {
+	t.Parallel()
+
+	catalog := newTestCatalog(t)
+	ctx := context.Background()
+
+	vendor, err := catalog.InferVendor(ctx, "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("Failed to infer vendor: %v", err)
+	}
+
+	if diff := cmp.Diff("OpenAI", vendor); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := catalog.InferVendor(ctx, "llama3"); !errors.Is(err, gofabric.ErrAmbiguousModel) {
+		t.Fatalf("Expected ErrAmbiguousModel, got: %v", err)
+	}
+
+	if _, err := catalog.InferVendor(ctx, "gpt-4"); !errors.Is(err, gofabric.ErrUnknownModel) {
+		t.Fatalf("Expected ErrUnknownModel, got: %v", err)
+	}
+}
+
+func TestModelCatalogSuggest(t *testing.T) {
+	t.Parallel()
+
+	catalog := newTestCatalog(t)
+
+	suggestions, err := catalog.Suggest(context.Background(), "", "gpt4o")
+	if err != nil {
+		t.Fatalf("Failed to suggest models: %v", err)
+	}
+
+	want := []gofabric.VendorModel{{Vendor: "OpenAI", Model: "gpt-4o"}}
+	if diff := cmp.Diff(want, suggestions); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModelCatalogRefreshInterval(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/models/names" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		requests.Add(1)
+		_ = json.NewEncoder(w).Encode(testModels)
+	}))
+	defer ts.Close()
+
+	catalog := gofabric.NewModelCatalog(gofabric.NewClient(ts.URL), 20*time.Millisecond)
+	ctx := context.Background()
+
+	for range 3 {
+		if err := catalog.Validate(ctx, "OpenAI", "gpt-4o"); err != nil {
+			t.Fatalf("Expected valid model, got: %v", err)
+		}
+	}
+
+	if diff := cmp.Diff(int32(1), requests.Load()); diff != "" {
+		t.Fatalf("Requests mismatch (-want +got):\n%s", diff)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	if err := catalog.Validate(ctx, "OpenAI", "gpt-4o"); err != nil {
+		t.Fatalf("Expected valid model, got: %v", err)
+	}
+
+	// The stale list is refreshed in the background.
+	for deadline := time.Now().Add(time.Second); requests.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	if diff := cmp.Diff(int32(2), requests.Load()); diff != "" {
+		t.Fatalf("Requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModelCatalogSlowRefresh(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	unblock := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every fetch but the first one hangs until the end of the test.
+		if requests.Add(1) > 1 {
+			<-unblock
+		}
+
+		_ = json.NewEncoder(w).Encode(testModels)
+	}))
+	defer ts.Close()
+	defer close(unblock)
+
+	catalog := gofabric.NewModelCatalog(gofabric.NewClient(ts.URL), time.Millisecond)
+
+	if err := catalog.Validate(context.Background(), "OpenAI", "gpt-4o"); err != nil {
+		t.Fatalf("Expected valid model, got: %v", err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	// The stale list is used while the refresh hangs, which is shared by every caller.
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		err := catalog.Validate(ctx, "OpenAI", "gpt-4o")
+		cancel()
+
+		if err != nil {
+			t.Fatalf("Expected the stale list to be used, got: %v", err)
+		}
+	}
+
+	for deadline := time.Now().Add(time.Second); requests.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	if diff := cmp.Diff(int32(2), requests.Load()); diff != "" {
+		t.Fatalf("Requests mismatch (-want +got):\n%s", diff)
+	}
+
+	// An explicit refresh waits for the hanging fetch, until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := catalog.Refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestChatWithModelValidation(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetModels(testModels)
+
+	client := server.Client(gofabric.WithModelValidation(time.Minute))
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithUserInput("Hello"),
+		gofabric.WithModel("Gemini", "gemini-2.0-flsh"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build chat request: %v", err)
+	}
+
+	if _, err := client.Chat(context.Background(), chatRequest); !errors.Is(err, gofabric.ErrUnknownModel) {
+		t.Fatalf("Expected ErrUnknownModel, got: %v", err)
+	}
+
+	if len(server.ChatRequests()) != 0 {
+		t.Fatal("Expected the chat request not to be sent")
+	}
+}
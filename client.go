@@ -3,14 +3,17 @@ package gofabric
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
-
-	"github.com/tmaxmax/go-sse"
 )
 
 const (
@@ -20,12 +23,34 @@ const (
 
 // Client represents a client for the Fabric API server.
 type Client struct {
-	// The URL of the Fabric API server
-	host string
-	// The API key for authentication
-	apiKey string
+	// The URL of the Fabric API server, as specified
+	baseURL *url.URL
+	// The URL requests are sent to, which differs from baseURL for unix-domain sockets
+	requestURL *url.URL
+	// The error reported by every request when the client is misconfigured
+	configErr error
+	// The authenticator of the requests
+	authenticator Authenticator
+	// The TLS configuration of the connections, nil for the default one
+	tlsConfig *tls.Config
+	// The rate limiter throttling chat requests
+	rateLimiter *RateLimiter
 	// The HTTP client for making requests
 	httpClient *http.Client
+	// The policy deciding whether failed requests are retried
+	retryPolicy RetryPolicy
+	// Whether interrupted chat streams are resumed
+	streamResume bool
+	// The maximum number of reconnections when resuming a chat stream
+	maxStreamReconnects int
+	// The catalog used to validate the models of chat requests
+	modelCatalog *ModelCatalog
+	// The middleware wrapping every request
+	middleware []Middleware
+	// The Doer sending requests through the middleware
+	doer Doer
+	// The logger of requests and chat streams
+	logger *slog.Logger
 }
 
 // Option represents a function that configures the Client using the functional options pattern.
@@ -34,28 +59,26 @@ type Option func(*Client)
 // NewClient creates a new Client instance with the specified host and options.
 // The Client will use the default HTTP client with a timeout of 60 seconds.
 //
-// To set the API key, use the WithAPIKey option.
+// The host is the URL of the Fabric API server, which may include a base path, e.g.
+// "https://gateway.internal/fabric/api", or a unix-domain socket, e.g. "unix:///run/fabric.sock".
+// An invalid host is reported by every request; use New to validate it up front.
+//
+// To set the API key, use the WithAPIKey option, or WithAuthenticator for other credentials.
 // To customize the HTTP client, use the WithHTTPClient option.
+// To retry failed requests, use the WithRetryPolicy option.
+// To validate models before chatting, use the WithModelValidation option.
+// To hook into every request, use the WithMiddleware option.
+// To log requests, use the WithLogger option.
 func NewClient(host string, opts ...Option) *Client {
-	client := &Client{
-		host: host,
-		httpClient: &http.Client{
-			Timeout: defaultHTTPClientTimeout,
-		},
-	}
-
-	for _, opt := range opts {
-		opt(client)
-	}
+	client, _ := New(host, opts...)
 
 	return client
 }
 
-// WithAPIKey sets the API key for the client.
+// WithAPIKey sets the API key for the client, sent in the X-API-Key header. It is a shorthand for
+// WithAuthenticator(APIKey(apiKey)).
 func WithAPIKey(apiKey string) Option {
-	return func(c *Client) {
-		c.apiKey = apiKey
-	}
+	return WithAuthenticator(APIKey(apiKey))
 }
 
 // WithHTTPClient sets the HTTP client for the client.
@@ -65,33 +88,115 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// requestOption customizes an outgoing request before it is sent.
+type requestOption func(*http.Request)
+
+func withHeader(key string, value string) requestOption {
+	return func(req *http.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
 func (c *Client) doRequest(
 	ctx context.Context,
+	op operation,
 	method string,
 	path string,
 	body io.Reader,
+	opts ...requestOption,
 ) (*http.Response, error) {
-	parsedURL, err := url.Parse(c.host)
-	if err != nil {
-		return nil, fmt.Errorf("invalid host %q: %w", c.host, err)
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+
+	url := c.requestURL.JoinPath(path).String()
+
+	// The body is buffered so that it can be replayed if the request is retried.
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = io.ReadAll(body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %s %s: %w", method, url, err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doAttempt(ctx, op, attempt, method, url, bodyBytes, opts)
+		if err == nil {
+			return resp, nil
+		}
+
+		var authErr *authenticationError
+		if c.retryPolicy == nil || ctx.Err() != nil || errors.As(err, &authErr) {
+			return nil, err
+		}
+
+		// A non-200 response is received before any chat stream starts, so replaying it cannot
+		// duplicate streamed content. The server usually rejected the request (429, 503), but a
+		// gateway answering 502 or 504 may have forwarded it; the policy decides whether to take
+		// that risk. Transport errors are only retried for idempotent methods, since a POST may have
+		// been processed (or a chat stream started) before the connection failed.
+		var httpErr *HTTPError
+		var delay time.Duration
+		var retry bool
+		if errors.As(err, &httpErr) {
+			delay, retry = c.retryPolicy.Retry(attempt, httpErr.response, nil)
+		} else if isIdempotentMethod(method) {
+			delay, retry = c.retryPolicy.Retry(attempt, nil, err)
+		}
+
+		if !retry || !sleep(ctx, delay) {
+			return nil, err
+		}
 	}
+}
 
-	url := parsedURL.JoinPath(path).String()
+func (c *Client) doAttempt(
+	ctx context.Context,
+	op operation,
+	attempt int,
+	method string,
+	url string,
+	bodyBytes []byte,
+	opts []requestOption,
+) (*http.Response, error) {
+	var body io.Reader
+	if bodyBytes != nil {
+		body = bytes.NewReader(bodyBytes)
+	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %s %s: %w", method, url, err)
 	}
 
-	if c.apiKey != "" {
-		req.Header.Set(apiKeyHeaderName, c.apiKey)
+	if c.authenticator != nil {
+		if err := c.authenticator.Authenticate(req); err != nil {
+			return nil, &authenticationError{err: err}
+		}
 	}
 
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	start := time.Now()
+
+	resp, err := c.doer.Do(&Request{
+		Operation:   op.name,
+		EntityType:  op.entityType,
+		EntityName:  op.entityName,
+		ChatRequest: op.chatRequest,
+		Attempt:     attempt,
+		HTTP:        req,
+	})
+
+	c.logRequest(ctx, op, attempt, req, resp, err, time.Since(start))
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %s %s: %w", method, url, err)
 	}
@@ -110,6 +215,7 @@ func (c *Client) doRequest(
 			URL:        url,
 			StatusCode: resp.StatusCode,
 			Body:       body,
+			response:   resp,
 		}
 	}
 
@@ -123,9 +229,19 @@ func createEntity(
 	entityName string,
 	body io.Reader,
 ) error {
-	resp, err := client.doRequest(ctx, http.MethodPost, "/"+string(entityType)+"s/"+entityName, body)
+	if err := ValidateName(entityType, entityName); err != nil {
+		return &EntityError{Op: "create", EntityType: entityType, Name: entityName, Err: err}
+	}
+
+	resp, err := client.doRequest(
+		ctx,
+		entityOperation("Create%s", entityType, entityName),
+		http.MethodPost,
+		entityPath(entityType, entityName),
+		body,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to create %s `%s`: %w", entityType, entityName, err)
+		return &EntityError{Op: "create", EntityType: entityType, Name: entityName, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -133,9 +249,19 @@ func createEntity(
 }
 
 func deleteEntity(client *Client, ctx context.Context, entityType EntityType, entityName string) error {
-	resp, err := client.doRequest(ctx, http.MethodDelete, "/"+string(entityType)+"s/"+entityName, nil)
+	if err := ValidateName(entityType, entityName); err != nil {
+		return &EntityError{Op: "delete", EntityType: entityType, Name: entityName, Err: err}
+	}
+
+	resp, err := client.doRequest(
+		ctx,
+		entityOperation("Delete%s", entityType, entityName),
+		http.MethodDelete,
+		entityPath(entityType, entityName),
+		nil,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to delete %s `%s`: %w", entityType, entityName, err)
+		return &EntityError{Op: "delete", EntityType: entityType, Name: entityName, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -148,25 +274,30 @@ func entityExists(
 	entityType EntityType,
 	entityName string,
 ) (bool, error) {
-	resp, err := client.doRequest(ctx, http.MethodGet, "/"+string(entityType)+"s/exists/"+entityName, nil)
+	if err := ValidateName(entityType, entityName); err != nil {
+		return false, &EntityError{Op: "exists", EntityType: entityType, Name: entityName, Err: err}
+	}
+
+	resp, err := client.doRequest(
+		ctx,
+		entityOperation("%sExists", entityType, entityName),
+		http.MethodGet,
+		entityPath(entityType, "exists", entityName),
+		nil,
+	)
 	if err != nil {
-		return false, fmt.Errorf(
-			"failed to check if %s `%s` exists: %w",
-			entityType,
-			entityName,
-			err,
-		)
+		return false, &EntityError{Op: "exists", EntityType: entityType, Name: entityName, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	var exists bool
 	if err := json.NewDecoder(resp.Body).Decode(&exists); err != nil {
-		return false, fmt.Errorf(
-			"failed to decode %s existence check for `%s`: %w",
-			entityType,
-			entityName,
-			err,
-		)
+		return false, &EntityError{
+			Op:         "exists",
+			EntityType: entityType,
+			Name:       entityName,
+			Err:        decodeError(err),
+		}
 	}
 
 	return exists, nil
@@ -178,30 +309,51 @@ func getEntity[T Entity](
 	entityType EntityType,
 	entityName string,
 ) (*T, error) {
-	resp, err := client.doRequest(ctx, http.MethodGet, "/"+string(entityType)+"s/"+entityName, nil)
+	if err := ValidateName(entityType, entityName); err != nil {
+		return nil, &EntityError{Op: "get", EntityType: entityType, Name: entityName, Err: err}
+	}
+
+	resp, err := client.doRequest(
+		ctx,
+		entityOperation("Get%sMetadata", entityType, entityName),
+		http.MethodGet,
+		entityPath(entityType, entityName),
+		nil,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get %s `%s`: %w", entityType, entityName, err)
+		return nil, &EntityError{Op: "get", EntityType: entityType, Name: entityName, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	var entity T
 	if err := json.NewDecoder(resp.Body).Decode(&entity); err != nil {
-		return nil, fmt.Errorf("failed to decode %s `%s`: %w", entityType, entityName, err)
+		return nil, &EntityError{
+			Op:         "get",
+			EntityType: entityType,
+			Name:       entityName,
+			Err:        decodeError(err),
+		}
 	}
 
 	return &entity, nil
 }
 
-func listEntity(client *Client, ctx context.Context, entityType string) ([]string, error) {
-	resp, err := client.doRequest(ctx, http.MethodGet, "/"+entityType+"/names", nil)
+func listEntity(client *Client, ctx context.Context, entityType EntityType) ([]string, error) {
+	resp, err := client.doRequest(
+		ctx,
+		entityOperation("List%ss", entityType, ""),
+		http.MethodGet,
+		entityPath(entityType, "names"),
+		nil,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list %s: %w", entityType, err)
+		return nil, &EntityError{Op: "list", EntityType: entityType, Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	var entitys []string
 	if err := json.NewDecoder(resp.Body).Decode(&entitys); err != nil {
-		return nil, fmt.Errorf("failed to decode %s: %w", entityType, err)
+		return nil, &EntityError{Op: "list", EntityType: entityType, Err: decodeError(err)}
 	}
 
 	return entitys, nil
@@ -214,71 +366,88 @@ func renameEntity(
 	oldEntityName string,
 	newEntityName string,
 ) error {
+	for _, entityName := range []string{oldEntityName, newEntityName} {
+		if err := ValidateName(entityType, entityName); err != nil {
+			return &EntityError{
+				Op:         "rename",
+				EntityType: entityType,
+				Name:       oldEntityName,
+				NewName:    newEntityName,
+				Err:        err,
+			}
+		}
+	}
+
 	resp, err := client.doRequest(
 		ctx,
+		entityOperation("Rename%s", entityType, oldEntityName),
 		http.MethodPut,
-		"/"+string(entityType)+"s/rename/"+oldEntityName+"/"+newEntityName,
+		entityPath(entityType, "rename", oldEntityName, newEntityName),
 		nil,
 	)
 	if err != nil {
-		return fmt.Errorf(
-			"failed to rename %s `%s` to `%s`: %w",
-			entityType,
-			oldEntityName,
-			newEntityName,
-			err,
-		)
+		return &EntityError{
+			Op:         "rename",
+			EntityType: entityType,
+			Name:       oldEntityName,
+			NewName:    newEntityName,
+			Err:        err,
+		}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	return nil
 }
 
+// AppendToSession appends messages to a session, creating the session if it does not exist.
+//
+// The session is fetched, extended locally and written back, so messages appended concurrently by
+// another client between the two requests are lost.
+func (c *Client) AppendToSession(ctx context.Context, name string, messages ...Message) error {
+	session, err := c.GetSessionMetadata(ctx, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var history []Message
+	if session != nil {
+		history = session.Messages
+	}
+
+	return c.CreateSessionFromMessages(ctx, name, append(history, messages...))
+}
+
 // Chat initiates a chat session with the specified chat request.
+//
+// The returned channel receives the responses streamed by the server and is closed once the stream
+// completes, fails, or ctx is cancelled. Errors that end the stream are delivered as a final
+// response of type StreamResponseTypeError whose Err field holds the error. See WithStreamResume to
+// resume interrupted streams.
+//
+// The chat request is validated before it is sent, see ChatRequest.Validate and
+// WithModelValidation.
 func (c *Client) Chat(ctx context.Context, chatRequest *ChatRequest) (<-chan StreamResponse, error) {
-	data, err := json.Marshal(chatRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode chat request: %w", err)
-	}
+	op := operation{name: "Chat", chatRequest: chatRequest}
 
-	resp, err := c.doRequest(ctx, http.MethodPost, "/chat", bytes.NewReader(data))
+	data, resp, release, err := c.startChat(ctx, op)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initiate chat: %w", err)
+		return nil, err
 	}
 
 	streamResponseChannel := make(chan StreamResponse)
 
 	go func() {
-		defer func() { _ = resp.Body.Close() }()
 		defer close(streamResponseChannel)
+		defer release()
 
-		iterator := sse.Read(resp.Body, nil)
-
-		iterator(func(event sse.Event, err error) bool {
-			var streamResponse StreamResponse
-
-			if err != nil {
-				err = fmt.Errorf("failed to read SSE response: %w", err)
-			} else {
-				err = json.Unmarshal([]byte(event.Data), &streamResponse)
-				if err != nil {
-					err = fmt.Errorf("failed to parse SSE response: %w", err)
-				}
-			}
-
+		c.readStream(ctx, op, data, resp, func(streamResponse StreamResponse, err error) bool {
 			if err != nil {
-				streamResponse = StreamResponse{
-					Type:    string(StreamResponseTypeError),
-					Format:  "plain",
-					Content: err.Error(),
-				}
+				streamResponse = streamErrorResponse(err)
 			}
 
 			select {
 			case streamResponseChannel <- streamResponse:
-				// Stop iterating if an error has occurred OR if the server
-				// has sent the "complete" message.
-				return err == nil && streamResponse.Type != string(StreamResponseTypeComplete)
+				return err == nil
 			case <-ctx.Done():
 				return false
 			}
@@ -288,6 +457,125 @@ func (c *Client) Chat(ctx context.Context, chatRequest *ChatRequest) (<-chan Str
 	return streamResponseChannel, nil
 }
 
+// ChatComplete initiates a chat session with the specified chat request and waits for the stream to
+// complete, aggregating the streamed content into a ChatResult.
+//
+// If the stream ends before the server sends the "complete" message, ChatComplete returns a
+// *StreamInterruptedError carrying the content received so far.
+func (c *Client) ChatComplete(ctx context.Context, chatRequest *ChatRequest) (*ChatResult, error) {
+	return c.chatComplete(ctx, operation{name: "ChatComplete", chatRequest: chatRequest})
+}
+
+func (c *Client) chatComplete(ctx context.Context, op operation) (*ChatResult, error) {
+	var result ChatResult
+	var content strings.Builder
+
+	start := time.Now()
+
+	for streamResponse, err := range c.chatStream(ctx, op) {
+		if err != nil {
+			return nil, err
+		}
+
+		switch streamResponse.Type {
+		case string(StreamResponseTypeContent):
+			if content.Len() == 0 && len(result.Segments) == 0 {
+				result.TimeToFirstToken = time.Since(start)
+			}
+
+			content.WriteString(streamResponse.Content)
+
+			if n := len(result.Segments); n > 0 && result.Segments[n-1].Format == streamResponse.Format {
+				result.Segments[n-1].Content += streamResponse.Content
+			} else {
+				result.Segments = append(result.Segments, ChatSegment{
+					Format:  streamResponse.Format,
+					Content: streamResponse.Content,
+				})
+			}
+		case string(StreamResponseTypeComplete):
+			result.Content = content.String()
+			result.Duration = time.Since(start)
+
+			return &result, nil
+		}
+	}
+
+	return nil, &StreamInterruptedError{Content: content.String()}
+}
+
+// ChatStream initiates a chat session with the specified chat request and returns an iterator over
+// the responses streamed by the server.
+//
+// The request is only sent when iteration starts, and the stream is read on the caller's goroutine.
+// Breaking out of the loop stops the stream and closes the response body. Errors end the iteration
+// and are yielded with a zero StreamResponse, except for errors reported by the server, which are
+// yielded as a *StreamError alongside the corresponding StreamResponse. A stream that ends before the
+// server sends the "complete" message yields a *StreamInterruptedError.
+func (c *Client) ChatStream(ctx context.Context, chatRequest *ChatRequest) iter.Seq2[StreamResponse, error] {
+	return c.chatStream(ctx, operation{name: "ChatStream", chatRequest: chatRequest})
+}
+
+func (c *Client) chatStream(ctx context.Context, op operation) iter.Seq2[StreamResponse, error] {
+	return func(yield func(StreamResponse, error) bool) {
+		data, resp, release, err := c.startChat(ctx, op)
+		if err != nil {
+			yield(StreamResponse{}, err)
+
+			return
+		}
+		defer release()
+
+		c.readStream(ctx, op, data, resp, func(streamResponse StreamResponse, err error) bool {
+			if err == nil && streamResponse.Type == string(StreamResponseTypeError) {
+				yield(streamResponse, &StreamError{Message: streamResponse.Content})
+
+				return false
+			}
+
+			return yield(streamResponse, err)
+		})
+	}
+}
+
+// startChat sends the chat request of the operation and returns the encoded request along with the
+// response whose body holds the SSE stream, and the function releasing the stream slot of the rate
+// limiter, which must be called when the stream ends.
+func (c *Client) startChat(ctx context.Context, op operation) ([]byte, *http.Response, func(), error) {
+	chatRequest := op.chatRequest
+
+	if err := chatRequest.Validate(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if c.modelCatalog != nil {
+		if err := c.modelCatalog.validateChatRequest(ctx, chatRequest); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	data, err := json.Marshal(chatRequest)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode chat request: %w", err)
+	}
+
+	release := func() {}
+	if c.rateLimiter != nil {
+		if release, err = c.rateLimiter.Wait(ctx, chatRequest); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	resp, err := c.doRequest(ctx, op, http.MethodPost, "/chat", bytes.NewReader(data))
+	if err != nil {
+		release()
+
+		return nil, nil, nil, fmt.Errorf("failed to initiate chat: %w", err)
+	}
+
+	return data, resp, release, nil
+}
+
 // CreateContext creates a new context.
 func (c *Client) CreateContext(ctx context.Context, name string, body io.Reader) error {
 	return createEntity(c, ctx, EntityTypeContext, name, body)
@@ -303,6 +591,25 @@ func (c *Client) CreateSession(ctx context.Context, name string, body io.Reader)
 	return createEntity(c, ctx, EntityTypeSession, name, body)
 }
 
+// CreateSessionFromMessages creates a new session holding the given messages.
+func (c *Client) CreateSessionFromMessages(ctx context.Context, name string, messages []Message) error {
+	if messages == nil {
+		messages = []Message{}
+	}
+
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return &EntityError{
+			Op:         "create",
+			EntityType: EntityTypeSession,
+			Name:       name,
+			Err:        fmt.Errorf("failed to encode messages: %w", err),
+		}
+	}
+
+	return createEntity(c, ctx, EntityTypeSession, name, bytes.NewReader(data))
+}
+
 // DeleteContext deletes a context.
 func (c *Client) DeleteContext(ctx context.Context, name string) error {
 	return deleteEntity(c, ctx, EntityTypeContext, name)
@@ -320,7 +627,7 @@ func (c *Client) DeleteSession(ctx context.Context, name string) error {
 
 // GetConfig retrieves the configuration of fabric.
 func (c *Client) GetConfig(ctx context.Context) (*Config, error) {
-	resp, err := c.doRequest(ctx, http.MethodGet, "/config", nil)
+	resp, err := c.doRequest(ctx, operation{name: "GetConfig"}, http.MethodGet, "/config", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
@@ -328,9 +635,11 @@ func (c *Client) GetConfig(ctx context.Context) (*Config, error) {
 
 	var config Config
 	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
-		return nil, fmt.Errorf("failed to decode config: %w", err)
+		return nil, fmt.Errorf("failed to get config: %w", decodeError(err))
 	}
 
+	c.logger.DebugContext(ctx, "fabric config received", slog.Any("config", config))
+
 	return &config, nil
 }
 
@@ -351,12 +660,12 @@ func (c *Client) GetSessionMetadata(ctx context.Context, name string) (*Session,
 
 // ListContexts retrieves the list of contexts.
 func (c *Client) ListContexts(ctx context.Context) ([]string, error) {
-	return listEntity(c, ctx, "contexts")
+	return listEntity(c, ctx, EntityTypeContext)
 }
 
 // ListNames retrieves a list of models.
 func (c *Client) ListModels(ctx context.Context) (*AvailableModels, error) {
-	resp, err := c.doRequest(ctx, http.MethodGet, "/models/names", nil)
+	resp, err := c.doRequest(ctx, operation{name: "ListModels"}, http.MethodGet, "/models/names", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get models: %w", err)
 	}
@@ -364,7 +673,7 @@ func (c *Client) ListModels(ctx context.Context) (*AvailableModels, error) {
 
 	var availableModels AvailableModels
 	if err := json.NewDecoder(resp.Body).Decode(&availableModels); err != nil {
-		return nil, fmt.Errorf("failed to decode models: %w", err)
+		return nil, fmt.Errorf("failed to get models: %w", decodeError(err))
 	}
 
 	return &availableModels, nil
@@ -372,17 +681,17 @@ func (c *Client) ListModels(ctx context.Context) (*AvailableModels, error) {
 
 // ListPatterns retrieves the list of patterns.
 func (c *Client) ListPatterns(ctx context.Context) ([]string, error) {
-	return listEntity(c, ctx, "patterns")
+	return listEntity(c, ctx, EntityTypePattern)
 }
 
 // ListSessions retrieves the list of sessions.
 func (c *Client) ListSessions(ctx context.Context) ([]string, error) {
-	return listEntity(c, ctx, "sessions")
+	return listEntity(c, ctx, EntityTypeSession)
 }
 
 // ListStrategies retrieves a list of strategies.
 func (c *Client) ListStrategies(ctx context.Context) ([]Strategy, error) {
-	resp, err := c.doRequest(ctx, http.MethodGet, "/strategies", nil)
+	resp, err := c.doRequest(ctx, operation{name: "ListStrategies"}, http.MethodGet, "/strategies", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get strategies: %w", err)
 	}
@@ -390,7 +699,7 @@ func (c *Client) ListStrategies(ctx context.Context) ([]Strategy, error) {
 
 	var strategies []Strategy
 	if err := json.NewDecoder(resp.Body).Decode(&strategies); err != nil {
-		return nil, fmt.Errorf("failed to decode strategies: %w", err)
+		return nil, fmt.Errorf("failed to get strategies: %w", decodeError(err))
 	}
 
 	return strategies, nil
@@ -433,7 +742,15 @@ func (c *Client) UpdateConfig(ctx context.Context, config *Config) error {
 		return fmt.Errorf("failed to encode config: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, http.MethodPut, "/config/update", bytes.NewReader(data))
+	c.logger.DebugContext(ctx, "fabric config update", slog.Any("config", config))
+
+	resp, err := c.doRequest(
+		ctx,
+		operation{name: "UpdateConfig"},
+		http.MethodPut,
+		"/config/update",
+		bytes.NewReader(data),
+	)
 	if err != nil {
 		return fmt.Errorf("failed to update config: %w", err)
 	}
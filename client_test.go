@@ -3,14 +3,59 @@ package gofabric_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
 )
 
+func TestAppendToSession(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetSession(gofabric.Session{
+		Name:     "test",
+		Messages: []gofabric.Message{gofabric.UserMessage("Hi")},
+	})
+
+	client := server.Client()
+	ctx := context.Background()
+
+	if err := client.AppendToSession(ctx, "test", gofabric.AssistantMessage("Hello")); err != nil {
+		t.Fatalf("Failed to append to session: %v", err)
+	}
+
+	if err := client.AppendToSession(ctx, "new", gofabric.MetaMessage("seeded")); err != nil {
+		t.Fatalf("Failed to append to new session: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want []gofabric.Message
+	}{
+		{name: "test", want: []gofabric.Message{gofabric.UserMessage("Hi"), gofabric.AssistantMessage("Hello")}},
+		{name: "new", want: []gofabric.Message{gofabric.MetaMessage("seeded")}},
+	}
+
+	for _, tt := range tests {
+		session, err := client.GetSessionMetadata(ctx, tt.name)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+
+		if diff := cmp.Diff(tt.want, session.Messages); diff != "" {
+			t.Fatalf("Mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
 func TestChat(t *testing.T) {
 	t.Parallel()
 
@@ -43,6 +88,71 @@ func TestChat(t *testing.T) {
 	}
 }
 
+func TestChatComplete(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "", contentResponse("# Title\n"))
+		writeEvent(w, "", contentResponse("Body\n"))
+		writeEvent(w, "", gofabric.StreamResponse{
+			Type:    string(gofabric.StreamResponseTypeContent),
+			Format:  "mermaid",
+			Content: "graph TD",
+		})
+		writeEvent(w, "", completeResponse())
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+	result, err := client.ChatComplete(context.Background(), testChatRequest())
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	if diff := cmp.Diff("# Title\nBody\ngraph TD", result.Content); diff != "" {
+		t.Fatalf("Content mismatch (-want +got):\n%s", diff)
+	}
+
+	wantSegments := []gofabric.ChatSegment{
+		{Format: "markdown", Content: "# Title\nBody\n"},
+		{Format: "mermaid", Content: "graph TD"},
+	}
+	if diff := cmp.Diff(wantSegments, result.Segments); diff != "" {
+		t.Fatalf("Segments mismatch (-want +got):\n%s", diff)
+	}
+
+	if result.TimeToFirstToken < 0 || result.Duration < result.TimeToFirstToken {
+		t.Fatalf("Unexpected timings: %+v", result)
+	}
+}
+
+func TestChatCompleteIncomplete(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "", contentResponse("partial"))
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+	_, err := client.ChatComplete(context.Background(), testChatRequest())
+
+	var interruptedErr *gofabric.StreamInterruptedError
+	if !errors.As(err, &interruptedErr) {
+		t.Fatalf("Expected *StreamInterruptedError, got: %v", err)
+	}
+
+	if diff := cmp.Diff("partial", interruptedErr.Content); diff != "" {
+		t.Fatalf("Content mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestCreateContext(t *testing.T) {
 	t.Parallel()
 
@@ -100,6 +210,34 @@ func TestCreateSession(t *testing.T) {
 	}
 }
 
+func TestCreateSessionFromMessages(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/sessions/test" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		want := `[{"role":"system","content":"Be terse."},{"role":"user","content":"Hi"}]`
+		if diff := cmp.Diff(want, string(body)); diff != "" {
+			t.Errorf("Body mismatch (-want +got):\n%s", diff)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+	err := client.CreateSessionFromMessages(context.Background(), "test", []gofabric.Message{
+		gofabric.SystemMessage("Be terse."),
+		gofabric.UserMessage("Hi"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+}
+
 func TestDeleteContext(t *testing.T) {
 	t.Parallel()
 
@@ -248,10 +386,7 @@ func TestGetSession(t *testing.T) {
 
 	want := &gofabric.Session{
 		Name: "test",
-		Messages: []struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		}{
+		Messages: []gofabric.Message{
 			{Role: "role", Content: "content"},
 		},
 	}
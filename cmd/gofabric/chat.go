@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+// chatResult is the result of the chat command, as printed with the json and yaml output formats.
+type chatResult struct {
+	Content          string        `json:"content"`
+	Segments         []chatSegment `json:"segments"`
+	TimeToFirstToken string        `json:"timeToFirstToken"`
+	Duration         string        `json:"duration"`
+}
+
+type chatSegment struct {
+	Format  string `json:"format"`
+	Content string `json:"content"`
+}
+
+// chatFlags holds the flags of the chat command.
+type chatFlags struct {
+	vendor      string
+	model       string
+	pattern     string
+	context     string
+	strategy    string
+	session     string
+	language    string
+	temperature *float64
+	topP        *float64
+	raw         bool
+	variables   map[string]string
+	interactive bool
+}
+
+func (a *app) chatFlagSet(flags *chatFlags) *flag.FlagSet {
+	fs := a.flagSet("chat")
+
+	fs.StringVar(&flags.vendor, "vendor", "", "vendor of the model")
+	fs.StringVar(&flags.model, "model", "", "model to chat with")
+	fs.StringVar(&flags.pattern, "pattern", "", "pattern to apply")
+	fs.StringVar(&flags.context, "context", "", "context to use")
+	fs.StringVar(&flags.strategy, "strategy", "", "strategy to apply")
+	fs.StringVar(&flags.session, "session", "", "session to record the exchange in")
+	fs.StringVar(&flags.language, "language", "", "language of the response")
+	fs.Func("temperature", "temperature, between 0 and 2", floatFlag(&flags.temperature))
+	fs.Func("top-p", "nucleus sampling parameter, between 0 and 1", floatFlag(&flags.topP))
+	fs.BoolVar(&flags.raw, "raw", false, "request the raw model output")
+	fs.BoolVar(&flags.interactive, "i", false, "chat interactively, recording the conversation in --session")
+	fs.Func("var", "pattern variable as <name>=<value>, may be repeated", func(arg string) error {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("expected <name>=<value>, got %q", arg)
+		}
+
+		if flags.variables == nil {
+			flags.variables = make(map[string]string)
+		}
+		flags.variables[name] = value
+
+		return nil
+	})
+
+	return fs
+}
+
+// chatRequestOptions returns the options building a chat request from the flags.
+func (f *chatFlags) chatRequestOptions() []gofabric.ChatRequestOption {
+	opts := []gofabric.ChatRequestOption{
+		gofabric.WithModel(f.vendor, f.model),
+		gofabric.WithPattern(f.pattern),
+		gofabric.WithContextName(f.context),
+		gofabric.WithStrategy(f.strategy),
+		gofabric.WithSessionName(f.session),
+		gofabric.WithLanguage(f.language),
+		gofabric.WithVariables(f.variables),
+	}
+
+	if f.temperature != nil {
+		opts = append(opts, gofabric.WithTemperature(*f.temperature))
+	}
+
+	if f.topP != nil {
+		opts = append(opts, gofabric.WithTopP(*f.topP))
+	}
+
+	if f.raw {
+		opts = append(opts, gofabric.WithRaw())
+	}
+
+	return opts
+}
+
+// runChatCommand runs the chat command. The input is read from the arguments, or from the standard
+// input if there are none. With the table output format, the response is streamed to the standard
+// output as it is received.
+func (a *app) runChatCommand(ctx context.Context, args []string) error {
+	var flags chatFlags
+	fs := a.chatFlagSet(&flags)
+
+	args, err := a.parseFlags(fs, args, 0, -1)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	if flags.interactive {
+		if len(args) > 0 {
+			return a.usageError("chat: the input is read interactively with -i")
+		}
+
+		return a.runREPL(ctx, client, &flags)
+	}
+
+	input := strings.Join(args, " ")
+	if len(args) == 0 {
+		data, err := a.readInput("")
+		if err != nil {
+			return err
+		}
+
+		input = string(data)
+	}
+
+	chatRequest, err := gofabric.NewChatRequest(
+		append(flags.chatRequestOptions(), gofabric.WithUserInput(input))...,
+	)
+	if err != nil {
+		return err
+	}
+
+	if a.output != outputTable {
+		result, err := client.ChatComplete(ctx, chatRequest)
+		if err != nil {
+			return err
+		}
+
+		output := chatResult{
+			Content:          result.Content,
+			Segments:         []chatSegment{},
+			TimeToFirstToken: result.TimeToFirstToken.String(),
+			Duration:         result.Duration.String(),
+		}
+		for _, segment := range result.Segments {
+			output.Segments = append(output.Segments, chatSegment{Format: segment.Format, Content: segment.Content})
+		}
+
+		return a.print(output, table{})
+	}
+
+	return a.streamChat(ctx, client, chatRequest)
+}
+
+// streamChat writes the content of the chat response to the standard output as it is received,
+// rendering markdown when writing to a terminal.
+func (a *app) streamChat(ctx context.Context, client *gofabric.Client, chatRequest *gofabric.ChatRequest) error {
+	w := newResponseWriter(a.stdout, a.color)
+
+	for streamResponse, err := range client.ChatStream(ctx, chatRequest) {
+		if err != nil {
+			_ = w.flush()
+
+			return err
+		}
+
+		if streamResponse.Type != string(gofabric.StreamResponseTypeContent) {
+			continue
+		}
+
+		if err := w.write(streamResponse.Format, streamResponse.Content); err != nil {
+			return err
+		}
+	}
+
+	return w.flush()
+}
+
+// floatFlag returns a flag.Func setter storing the parsed value in *target.
+func floatFlag(target **float64) func(value string) error {
+	return func(value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		*target = &f
+
+		return nil
+	}
+}
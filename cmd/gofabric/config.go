@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+// runConfigCommand runs the subcommands of the config command.
+func (a *app) runConfigCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return a.usageError("missing config subcommand")
+	}
+
+	subcommand, args := args[0], args[1:]
+	fs := a.flagSet("config " + subcommand)
+
+	switch subcommand {
+	case "get":
+		showSecrets := fs.Bool("show-secrets", false, "print the API keys instead of masking them")
+
+		if _, err := a.parseFlags(fs, args, 0, 0); err != nil {
+			return err
+		}
+
+		client, err := a.client()
+		if err != nil {
+			return err
+		}
+
+		config, err := client.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		values := config.Providers()
+
+		if !*showSecrets {
+			for provider, value := range values {
+				values[provider] = maskSecret(value)
+				config.Set(provider, values[provider])
+			}
+		}
+
+		t := table{header: []string{"PROVIDER", "VALUE"}}
+		for _, provider := range configProviders(values) {
+			t.rows = append(t.rows, []string{provider, values[provider]})
+		}
+
+		return a.print(config, t)
+	case "set":
+		args, err := a.parseFlags(fs, args, 1, -1)
+		if err != nil {
+			return err
+		}
+
+		client, err := a.client()
+		if err != nil {
+			return err
+		}
+
+		// The providers are those of the server, which may know vendors newer than this package.
+		config, err := client.GetConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		values := make(map[string]string)
+
+		for _, arg := range args {
+			provider, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				return a.usageError("config set: expected <provider>=<value>, got %q", arg)
+			}
+
+			if _, ok := config.Get(provider); !ok {
+				return a.usageError(
+					"config set: unknown provider %q, expected one of %s",
+					provider,
+					strings.Join(configProviders(config.Providers()), ", "),
+				)
+			}
+
+			values[provider] = value
+		}
+
+		return client.PatchConfig(ctx, gofabric.ConfigUpdate{Providers: values}.Apply)
+	default:
+		return a.usageError("unknown config subcommand %q", subcommand)
+	}
+}
+
+// configProviders returns the names of the providers of the config fields in declaration order,
+// followed by the other providers of values in lexical order.
+func configProviders(values map[string]string) []string {
+	var providers []string
+
+	configType := reflect.TypeFor[gofabric.Config]()
+	for i := range configType.NumField() {
+		name, _, _ := strings.Cut(configType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			providers = append(providers, name)
+		}
+	}
+
+	var extra []string
+	for provider := range values {
+		if !slices.Contains(providers, provider) {
+			extra = append(extra, provider)
+		}
+	}
+
+	slices.Sort(extra)
+
+	return append(providers, extra...)
+}
+
+// maskSecret masks all but the last 4 characters of value, or all of them if value is too short
+// for its end to be shown safely.
+func maskSecret(value string) string {
+	const shown = 4
+
+	if value == "" {
+		return ""
+	}
+
+	if runes := []rune(value); len(runes) > 2*shown {
+		return "****" + string(runes[len(runes)-shown:])
+	}
+
+	return "****"
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+// entityCommands holds the client methods of an entity type.
+type entityCommands struct {
+	list   func(ctx context.Context) ([]string, error)
+	get    func(ctx context.Context, name string) (any, table, error)
+	create func(ctx context.Context, name string, body []byte) error
+	rename func(ctx context.Context, oldName string, newName string) error
+	delete func(ctx context.Context, name string) error
+	exists func(ctx context.Context, name string) (bool, error)
+}
+
+func newEntityCommands(client *gofabric.Client, entityType gofabric.EntityType) entityCommands {
+	switch entityType {
+	case gofabric.EntityTypeContext:
+		return entityCommands{
+			list: client.ListContexts,
+			get: func(ctx context.Context, name string) (any, table, error) {
+				entity, err := client.GetContextMetadata(ctx, name)
+				if err != nil {
+					return nil, table{}, err
+				}
+
+				return entity, table{
+					header: []string{"NAME", "CONTENT"},
+					rows:   [][]string{{entity.Name, entity.Content}},
+				}, nil
+			},
+			create: func(ctx context.Context, name string, body []byte) error {
+				return client.CreateContext(ctx, name, bytes.NewReader(body))
+			},
+			rename: client.RenameContext,
+			delete: client.DeleteContext,
+			exists: client.ContextExists,
+		}
+	case gofabric.EntityTypePattern:
+		return entityCommands{
+			list: client.ListPatterns,
+			get: func(ctx context.Context, name string) (any, table, error) {
+				entity, err := client.GetPatternMetadata(ctx, name)
+				if err != nil {
+					return nil, table{}, err
+				}
+
+				return entity, table{
+					header: []string{"NAME", "DESCRIPTION", "PATTERN"},
+					rows:   [][]string{{entity.Name, entity.Description, entity.Pattern}},
+				}, nil
+			},
+			create: func(ctx context.Context, name string, body []byte) error {
+				return client.CreatePattern(ctx, name, bytes.NewReader(body))
+			},
+			rename: client.RenamePattern,
+			delete: client.DeletePattern,
+			exists: client.PatternExists,
+		}
+	default:
+		return entityCommands{
+			list: client.ListSessions,
+			get: func(ctx context.Context, name string) (any, table, error) {
+				entity, err := client.GetSessionMetadata(ctx, name)
+				if err != nil {
+					return nil, table{}, err
+				}
+
+				t := table{header: []string{"ROLE", "CONTENT"}}
+				for _, message := range entity.Messages {
+					t.rows = append(t.rows, []string{string(message.Role), message.Content})
+				}
+
+				return entity, t, nil
+			},
+			create: func(ctx context.Context, name string, body []byte) error {
+				return client.CreateSession(ctx, name, bytes.NewReader(body))
+			},
+			rename: client.RenameSession,
+			delete: client.DeleteSession,
+			exists: client.SessionExists,
+		}
+	}
+}
+
+// runEntityCommand runs the subcommands of the patterns, contexts and sessions commands.
+func (a *app) runEntityCommand(ctx context.Context, entityType gofabric.EntityType, args []string) error {
+	if len(args) == 0 {
+		return a.usageError("missing %ss subcommand", entityType)
+	}
+
+	subcommand, args := args[0], args[1:]
+	fs := a.flagSet(string(entityType) + "s " + subcommand)
+
+	var minArgs, maxArgs int
+	var file *string
+
+	switch subcommand {
+	case "list":
+		minArgs, maxArgs = 0, 0
+	case "get", "delete", "exists":
+		minArgs, maxArgs = 1, 1
+	case "create":
+		minArgs, maxArgs = 1, 1
+		file = fs.String("file", "", "file holding the content, - or empty to read the standard input")
+	case "rename":
+		minArgs, maxArgs = 2, 2
+	default:
+		return a.usageError("unknown %ss subcommand %q", entityType, subcommand)
+	}
+
+	args, err := a.parseFlags(fs, args, minArgs, maxArgs)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	commands := newEntityCommands(client, entityType)
+
+	switch subcommand {
+	case "list":
+		names, err := commands.list(ctx)
+		if err != nil {
+			return err
+		}
+
+		if names == nil {
+			names = []string{}
+		}
+
+		t := table{header: []string{"NAME"}}
+		for _, name := range names {
+			t.rows = append(t.rows, []string{name})
+		}
+
+		return a.print(names, t)
+	case "get":
+		entity, t, err := commands.get(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		return a.print(entity, t)
+	case "create":
+		body, err := a.readInput(*file)
+		if err != nil {
+			return err
+		}
+
+		return commands.create(ctx, args[0], body)
+	case "rename":
+		return commands.rename(ctx, args[0], args[1])
+	case "delete":
+		return commands.delete(ctx, args[0])
+	default:
+		exists, err := commands.exists(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := a.print(exists, table{rows: [][]string{{strconv.FormatBool(exists)}}}); err != nil {
+			return err
+		}
+
+		if !exists {
+			return errEntityNotFound
+		}
+
+		return nil
+	}
+}
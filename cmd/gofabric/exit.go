@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+// Exit statuses of the command.
+const (
+	exitOK                = 0
+	exitError             = 1
+	exitUsage             = 2
+	exitNotFound          = 3
+	exitUnauthorized      = 4
+	exitConflict          = 5
+	exitInvalidRequest    = 6
+	exitServerUnavailable = 7
+	exitHTTPError         = 8
+)
+
+// errEntityNotFound is returned by the exists subcommands when the entity does not exist, after the
+// result was printed.
+var errEntityNotFound = errors.New("entity not found")
+
+// exitCode returns the exit status for err. Failed requests are mapped from the status code of the
+// *gofabric.HTTPError: 404 to 3, 401 and 403 to 4, 409 to 5, 400 and client-side validation errors
+// to 6, 5xx to 7 and any other status code to 8.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+
+	if errors.Is(err, errEntityNotFound) {
+		return exitNotFound
+	}
+
+	if errors.Is(err, gofabric.ErrInvalidRequest) ||
+		errors.Is(err, gofabric.ErrInvalidName) ||
+		errors.Is(err, gofabric.ErrUnknownModel) {
+		return exitInvalidRequest
+	}
+
+	var httpErr *gofabric.HTTPError
+	if !errors.As(err, &httpErr) {
+		return exitError
+	}
+
+	switch {
+	case httpErr.StatusCode == http.StatusNotFound:
+		return exitNotFound
+	case httpErr.StatusCode == http.StatusUnauthorized, httpErr.StatusCode == http.StatusForbidden:
+		return exitUnauthorized
+	case httpErr.StatusCode == http.StatusConflict:
+		return exitConflict
+	case httpErr.StatusCode == http.StatusBadRequest:
+		return exitInvalidRequest
+	case httpErr.StatusCode >= http.StatusInternalServerError:
+		return exitServerUnavailable
+	default:
+		return exitHTTPError
+	}
+}
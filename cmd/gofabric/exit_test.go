@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	httpError := func(statusCode int) error {
+		return &gofabric.EntityError{
+			Op:         "get",
+			EntityType: gofabric.EntityTypePattern,
+			Name:       "summarize",
+			Err:        &gofabric.HTTPError{StatusCode: statusCode},
+		}
+	}
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: exitOK},
+		{err: errors.New("boom"), want: exitError},
+		{err: fmt.Errorf("wrapped: %w", errUsage), want: exitUsage},
+		{err: httpError(http.StatusNotFound), want: exitNotFound},
+		{err: httpError(http.StatusUnauthorized), want: exitUnauthorized},
+		{err: httpError(http.StatusForbidden), want: exitUnauthorized},
+		{err: httpError(http.StatusConflict), want: exitConflict},
+		{err: httpError(http.StatusBadRequest), want: exitInvalidRequest},
+		{err: &gofabric.ValidationError{Field: "prompts", Message: "invalid"}, want: exitInvalidRequest},
+		{
+			err:  &gofabric.InvalidNameError{EntityType: gofabric.EntityTypePattern, Name: "..", Reason: "invalid"},
+			want: exitInvalidRequest,
+		},
+		{err: httpError(http.StatusBadGateway), want: exitServerUnavailable},
+		{err: httpError(http.StatusTeapot), want: exitHTTPError},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.want, exitCode(tt.err)); diff != "" {
+			t.Errorf("exitCode(%v) mismatch (-want +got):\n%s", tt.err, diff)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+// model is a model offered by a vendor, as printed by the models command.
+type model struct {
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+}
+
+// runModelsCommand runs the models command.
+func (a *app) runModelsCommand(ctx context.Context, args []string) error {
+	fs := a.flagSet("models")
+	vendor := fs.String("vendor", "", "only list the models of the vendor")
+
+	if _, err := a.parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	vendorModels := []model{}
+	for _, vendorName := range slices.Sorted(maps.Keys(models.Vendors)) {
+		if *vendor != "" && !strings.EqualFold(vendorName, *vendor) {
+			continue
+		}
+
+		for _, modelName := range models.Vendors[vendorName] {
+			vendorModels = append(vendorModels, model{Vendor: vendorName, Model: modelName})
+		}
+	}
+
+	t := table{header: []string{"VENDOR", "MODEL"}}
+	for _, vendorModel := range vendorModels {
+		t.rows = append(t.rows, []string{vendorModel.Vendor, vendorModel.Model})
+	}
+
+	return a.print(vendorModels, t)
+}
+
+// runStrategiesCommand runs the strategies command.
+func (a *app) runStrategiesCommand(ctx context.Context, args []string) error {
+	fs := a.flagSet("strategies")
+
+	if _, err := a.parseFlags(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	strategies, err := client.ListStrategies(ctx)
+	if err != nil {
+		return err
+	}
+
+	if strategies == nil {
+		strategies = []gofabric.Strategy{}
+	}
+
+	t := table{header: []string{"NAME", "DESCRIPTION"}}
+	for _, strategy := range strategies {
+		t.rows = append(t.rows, []string{strategy.Name, strategy.Description})
+	}
+
+	return a.print(strategies, t)
+}
@@ -0,0 +1,284 @@
+// Command gofabric manages a Fabric API server from the command line.
+//
+// Usage:
+//
+//	gofabric [flags] <command> [<subcommand>] [flags] [arguments]
+//
+// The commands are:
+//
+//	patterns, contexts, sessions   list, get, create, rename, delete or check the existence of entities
+//	config                         get or set the provider configuration
+//	models                         list the available models
+//	strategies                     list the available strategies
+//	chat                           chat with a model, streaming the response, or interactively with -i
+//
+// The server URL is read from the FABRIC_SERVER_URL environment variable and the API key from the
+// FABRIC_API_KEY environment variable, unless set with the --server and --api-key flags. The output
+// format is selected with --output: table (the default), json or yaml.
+//
+// The exit status is 0 on success, 2 on usage errors and mapped from the HTTP status code of the
+// failed request otherwise; see exitCode.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+const (
+	serverURLEnvName = "FABRIC_SERVER_URL"
+	apiKeyEnvName    = "FABRIC_API_KEY"
+)
+
+const usage = `Usage: gofabric [flags] <command> [<subcommand>] [flags] [arguments]
+
+Commands:
+  patterns|contexts|sessions list
+  patterns|contexts|sessions get <name>
+  patterns|contexts|sessions create <name> [--file <path>]
+  patterns|contexts|sessions rename <name> <new-name>
+  patterns|contexts|sessions delete <name>
+  patterns|contexts|sessions exists <name>
+  config get [--show-secrets]
+  config set <provider>=<value>...
+  models
+  strategies
+  chat [flags] [input...]
+  chat -i [flags]
+
+Flags:
+  --server    URL of the Fabric server (default $FABRIC_SERVER_URL)
+  --api-key   API key of the Fabric server (default $FABRIC_API_KEY)
+  --output    output format: table, json or yaml (default table)
+
+Run "gofabric <command> [<subcommand>] --help" for the flags of a command.
+`
+
+// errUsage is returned for invalid command lines, after the problem was reported.
+var errUsage = errors.New("usage error")
+
+// app holds the state of a single invocation of the command.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	serverURL string
+	apiKey    string
+	output    string
+
+	// Whether the standard output is a terminal rendering ANSI escape sequences
+	color bool
+
+	interruptMu sync.Mutex
+	// The function called when the command is interrupted, cancelling the command by default
+	onInterrupt func()
+}
+
+func main() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.LookupEnv, interrupts))
+}
+
+// run runs the command with the specified arguments and returns its exit status. Values received
+// from interrupts interrupt the command, see setInterruptHandler.
+func run(
+	ctx context.Context,
+	args []string,
+	stdin io.Reader,
+	stdout io.Writer,
+	stderr io.Writer,
+	lookupEnv func(key string) (string, bool),
+	interrupts <-chan os.Signal,
+) int {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, output: outputTable, onInterrupt: cancel}
+	a.serverURL, _ = lookupEnv(serverURLEnvName)
+	a.apiKey, _ = lookupEnv(apiKeyEnvName)
+
+	if _, noColor := lookupEnv("NO_COLOR"); !noColor {
+		a.color = isTerminal(stdout)
+	}
+
+	go a.handleInterrupts(ctx, interrupts)
+
+	fs := a.flagSet("gofabric")
+	fs.Usage = func() { _, _ = fmt.Fprint(stderr, usage) }
+
+	if err := fs.Parse(args); err != nil {
+		return exitCode(errUsage)
+	}
+
+	err := a.dispatch(ctx, fs.Args())
+	if err != nil && !errors.Is(err, errUsage) && !errors.Is(err, errEntityNotFound) {
+		_, _ = fmt.Fprintf(stderr, "gofabric: %v\n", err)
+	}
+
+	return exitCode(err)
+}
+
+func (a *app) dispatch(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return a.usageError("missing command")
+	}
+
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "patterns":
+		return a.runEntityCommand(ctx, gofabric.EntityTypePattern, args)
+	case "contexts":
+		return a.runEntityCommand(ctx, gofabric.EntityTypeContext, args)
+	case "sessions":
+		return a.runEntityCommand(ctx, gofabric.EntityTypeSession, args)
+	case "config":
+		return a.runConfigCommand(ctx, args)
+	case "models":
+		return a.runModelsCommand(ctx, args)
+	case "strategies":
+		return a.runStrategiesCommand(ctx, args)
+	case "chat":
+		return a.runChatCommand(ctx, args)
+	case "help":
+		_, _ = fmt.Fprint(a.stdout, usage)
+
+		return nil
+	default:
+		return a.usageError("unknown command %q", command)
+	}
+}
+
+// handleInterrupts calls the interrupt handler for every value received from interrupts, until ctx is
+// done.
+func (a *app) handleInterrupts(ctx context.Context, interrupts <-chan os.Signal) {
+	for {
+		select {
+		case <-interrupts:
+			a.interruptMu.Lock()
+			onInterrupt := a.onInterrupt
+			a.interruptMu.Unlock()
+
+			onInterrupt()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// setInterruptHandler sets the function called when the command is interrupted and returns the
+// previous one.
+func (a *app) setInterruptHandler(onInterrupt func()) func() {
+	a.interruptMu.Lock()
+	defer a.interruptMu.Unlock()
+
+	previous := a.onInterrupt
+	a.onInterrupt = onInterrupt
+
+	return previous
+}
+
+// flagSet returns a flag set holding the flags shared by every command, so that they can also be
+// set after the command.
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+
+	fs.StringVar(&a.serverURL, "server", a.serverURL, "URL of the Fabric server")
+	fs.StringVar(&a.apiKey, "api-key", a.apiKey, "API key of the Fabric server")
+	fs.StringVar(&a.output, "output", a.output, "output format: table, json or yaml")
+
+	return fs
+}
+
+// parseFlags parses the flags of a command, which may be interleaved with its arguments, and checks
+// the number of arguments.
+func (a *app) parseFlags(fs *flag.FlagSet, args []string, minArgs int, maxArgs int) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	switch {
+	case len(positional) < minArgs:
+		return nil, a.usageError("%s: missing arguments", fs.Name())
+	case maxArgs >= 0 && len(positional) > maxArgs:
+		return nil, a.usageError("%s: too many arguments", fs.Name())
+	}
+
+	if !isOutputFormat(a.output) {
+		return nil, a.usageError("unknown output format %q", a.output)
+	}
+
+	return positional, nil
+}
+
+// client returns a client for the configured server.
+func (a *app) client() (*gofabric.Client, error) {
+	if a.serverURL == "" {
+		return nil, a.usageError("the server URL must be set with --server or %s", serverURLEnvName)
+	}
+
+	var opts []gofabric.Option
+	if a.apiKey != "" {
+		opts = append(opts, gofabric.WithAPIKey(a.apiKey))
+	}
+
+	client, err := gofabric.New(a.serverURL, opts...)
+	if err != nil {
+		return nil, a.usageError("%v", err)
+	}
+
+	return client, nil
+}
+
+// usageError reports a usage error and returns errUsage.
+func (a *app) usageError(format string, args ...any) error {
+	_, _ = fmt.Fprintf(a.stderr, "gofabric: %s\n\n%s", fmt.Sprintf(format, args...), usage)
+
+	return errUsage
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// readInput returns the content of the file at path, or of the standard input if path is empty or
+// "-".
+func (a *app) readInput(path string) ([]byte, error) {
+	if path == "" || path == "-" {
+		return io.ReadAll(a.stdin)
+	}
+
+	return os.ReadFile(path)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCommand(t *testing.T, server *gofabrictest.Server, stdin string, args ...string) result {
+	t.Helper()
+
+	env := map[string]string{serverURLEnvName: server.URL, apiKeyEnvName: "secret"}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+
+		return value, ok
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr, lookupEnv, nil)
+
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func newTestServer(t *testing.T) *gofabrictest.Server {
+	t.Helper()
+
+	server := gofabrictest.NewServer(gofabrictest.WithAPIKey("secret"))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestEntityCommands(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	if got := runCommand(t, server, "Summarize {{input}}", "patterns", "create", "summarize"); got.code != 0 {
+		t.Fatalf("Failed to create pattern: %+v", got)
+	}
+
+	if got := runCommand(t, server, "", "patterns", "rename", "summarize", "summarize_v2"); got.code != 0 {
+		t.Fatalf("Failed to rename pattern: %+v", got)
+	}
+
+	got := runCommand(t, server, "", "patterns", "list", "--output", "json")
+	if diff := cmp.Diff(result{stdout: "[\n  \"summarize_v2\"\n]\n"}, got, cmp.AllowUnexported(result{})); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	got = runCommand(t, server, "", "patterns", "get", "summarize_v2")
+	want := "NAME          DESCRIPTION  PATTERN\nsummarize_v2               Summarize {{input}}\n"
+	if diff := cmp.Diff(want, got.stdout); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	got = runCommand(t, server, "", "--output", "yaml", "patterns", "exists", "summarize")
+	if diff := cmp.Diff(result{code: exitNotFound, stdout: "false\n"}, got, cmp.AllowUnexported(result{})); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := runCommand(t, server, "", "patterns", "delete", "summarize_v2"); got.code != 0 {
+		t.Fatalf("Failed to delete pattern: %+v", got)
+	}
+
+	got = runCommand(t, server, "", "patterns", "get", "summarize_v2")
+	if diff := cmp.Diff(exitNotFound, got.code); diff != "" {
+		t.Fatalf("Exit code mismatch (-want +got):\n%s", diff)
+	}
+
+	if !strings.Contains(got.stderr, "failed to get pattern `summarize_v2`") {
+		t.Fatalf("Unexpected error output: %q", got.stderr)
+	}
+}
+
+func TestSessionsGet(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetSession(gofabric.Session{
+		Name:     "review",
+		Messages: []gofabric.Message{gofabric.UserMessage("Hello"), gofabric.AssistantMessage("Hi\nthere")},
+	})
+
+	got := runCommand(t, server, "", "sessions", "get", "review", "--output", "yaml")
+	want := `name: review
+messages:
+  -
+    role: user
+    content: Hello
+  -
+    role: assistant
+    content: |-
+      Hi
+      there
+`
+	if diff := cmp.Diff(want, got.stdout); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetConfig(gofabric.Config{Anthropic: "anthropic-key"})
+
+	if got := runCommand(t, server, "", "config", "set", "openai=openai-key", "ollama=http://localhost:11434"); got.code != 0 {
+		t.Fatalf("Failed to set config: %+v", got)
+	}
+
+	want := gofabric.Config{Anthropic: "anthropic-key", OpenAI: "openai-key", Ollama: "http://localhost:11434"}
+	if diff := cmp.Diff(want, server.Config()); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	got := runCommand(t, server, "", "config", "set", "unknown=value")
+	if diff := cmp.Diff(exitUsage, got.code); diff != "" {
+		t.Fatalf("Exit code mismatch (-want +got):\n%s", diff)
+	}
+
+	got = runCommand(t, server, "", "config", "get", "--show-secrets")
+	if !strings.Contains(got.stdout, "openai      openai-key\n") {
+		t.Fatalf("Unexpected output: %q", got.stdout)
+	}
+}
+
+func TestConfigSetUnknownVendor(t *testing.T) {
+	t.Parallel()
+
+	// The server knows a vendor that is not a field of gofabric.Config.
+	server := newTestServer(t)
+	server.SetConfig(gofabric.Config{Extra: map[string]json.RawMessage{"mistral": []byte(`""`)}})
+
+	if got := runCommand(t, server, "", "config", "set", "mistral=mistral-key"); got.code != 0 {
+		t.Fatalf("Failed to set config: %+v", got)
+	}
+
+	if value, _ := server.Config().Get("mistral"); value != "mistral-key" {
+		t.Fatalf("Expected the key of the vendor to be set, got %q", value)
+	}
+
+	got := runCommand(t, server, "", "config", "get", "--show-secrets")
+	if !strings.Contains(got.stdout, "mistral     mistral-key\n") {
+		t.Fatalf("Unexpected output: %q", got.stdout)
+	}
+}
+
+func TestConfigGetMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetConfig(gofabric.Config{Anthropic: "sk-ant-0123456789", Groq: "short"})
+
+	got := runCommand(t, server, "", "config", "get")
+	for _, want := range []string{"anthropic   ****6789\n", "groq        ****\n", "openai      \n"} {
+		if !strings.Contains(got.stdout, want) {
+			t.Fatalf("Expected the output to contain %q, got %q", want, got.stdout)
+		}
+	}
+
+	for _, output := range []string{"json", "yaml"} {
+		got := runCommand(t, server, "", "config", "get", "--output", output)
+		masked := strings.Contains(got.stdout, "****6789") && !strings.Contains(got.stdout, "0123456789")
+		if got.code != 0 || !masked {
+			t.Fatalf("Expected the %s output to mask the keys, got %+v", output, got)
+		}
+	}
+}
+
+func TestModelsCommand(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetModels(gofabric.AvailableModels{
+		Models:  []string{"gemini-2.0-flash", "gpt-4o"},
+		Vendors: map[string][]string{"OpenAI": {"gpt-4o"}, "Gemini": {"gemini-2.0-flash"}},
+	})
+
+	got := runCommand(t, server, "", "models")
+	want := "VENDOR  MODEL\nGemini  gemini-2.0-flash\nOpenAI  gpt-4o\n"
+	if diff := cmp.Diff(want, got.stdout); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	got = runCommand(t, server, "", "models", "--vendor", "openai", "--output", "json")
+	want = "[\n  {\n    \"vendor\": \"OpenAI\",\n    \"model\": \"gpt-4o\"\n  }\n]\n"
+	if diff := cmp.Diff(want, got.stdout); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatCommand(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	got := runCommand(t, server, "", "chat", "--pattern", "summarize", "--var", "lang=fr", "Hello,", "world")
+	if diff := cmp.Diff(result{stdout: "Hello, world\n"}, got, cmp.AllowUnexported(result{})); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	want := gofabric.PromptRequest{
+		UserInput:   "Hello, world",
+		PatternName: "summarize",
+		Variables:   map[string]string{"lang": "fr"},
+	}
+	if diff := cmp.Diff(want, server.ChatRequests()[0].Prompts[0]); diff != "" {
+		t.Fatalf("Prompt mismatch (-want +got):\n%s", diff)
+	}
+
+	got = runCommand(t, server, "From stdin", "chat", "--output", "json")
+	if !strings.Contains(got.stdout, `"content": "From stdin"`) {
+		t.Fatalf("Unexpected output: %q", got.stdout)
+	}
+
+	got = runCommand(t, server, "", "chat", "--temperature", "3", "Hello")
+	if diff := cmp.Diff(exitInvalidRequest, got.code); diff != "" {
+		t.Fatalf("Exit code mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "missing command", args: nil, want: exitUsage},
+		{name: "unknown command", args: []string{"unknown"}, want: exitUsage},
+		{name: "missing argument", args: []string{"contexts", "get"}, want: exitUsage},
+		{name: "unknown output", args: []string{"contexts", "list", "--output", "xml"}, want: exitUsage},
+		{name: "unauthorized", args: []string{"--api-key", "wrong", "contexts", "list"}, want: exitUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tt.want, runCommand(t, server, "", tt.args...).code); diff != "" {
+				t.Fatalf("Exit code mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
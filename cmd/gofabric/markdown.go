@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ANSI escape sequences used to render markdown.
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiCyan      = "\x1b[36m"
+	ansiMagenta   = "\x1b[35m"
+)
+
+var (
+	markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBulletPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	markdownCodePattern    = regexp.MustCompile("`([^`]+)`")
+	markdownBoldPattern    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownLinkPattern    = regexp.MustCompile(`\[([^\[\]]+)\]\(([^)\s]+)\)`)
+)
+
+// markdownRenderer renders streamed markdown content to a terminal with ANSI escape sequences.
+//
+// Content is rendered a line at a time, once the line is complete, since the markup of a line may
+// span several chunks of the stream.
+type markdownRenderer struct {
+	w      io.Writer
+	line   strings.Builder
+	inCode bool
+}
+
+func newMarkdownRenderer(w io.Writer) *markdownRenderer {
+	return &markdownRenderer{w: w}
+}
+
+// Write renders the complete lines of content, buffering the last line until it is complete.
+func (r *markdownRenderer) Write(content string) error {
+	for {
+		before, after, found := strings.Cut(content, "\n")
+		r.line.WriteString(before)
+		if !found {
+			return nil
+		}
+
+		if _, err := io.WriteString(r.w, r.renderLine(r.line.String())+"\n"); err != nil {
+			return err
+		}
+
+		r.line.Reset()
+		content = after
+	}
+}
+
+// Flush renders the buffered incomplete line, if any, followed by a newline.
+func (r *markdownRenderer) Flush() error {
+	if r.line.Len() == 0 {
+		return nil
+	}
+
+	line := r.renderLine(r.line.String())
+	r.line.Reset()
+
+	_, err := io.WriteString(r.w, line+"\n")
+
+	return err
+}
+
+func (r *markdownRenderer) renderLine(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		r.inCode = !r.inCode
+
+		return ansiDim + line + ansiReset
+	}
+
+	if r.inCode {
+		return ansiCyan + line + ansiReset
+	}
+
+	if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+		return ansiBold + ansiUnderline + renderInlineMarkdown(match[2]) + ansiReset
+	}
+
+	if match := markdownBulletPattern.FindStringSubmatch(line); match != nil {
+		return match[1] + "• " + renderInlineMarkdown(match[2])
+	}
+
+	if strings.HasPrefix(line, ">") {
+		return ansiDim + ansiItalic + renderInlineMarkdown(strings.TrimSpace(line[1:])) + ansiReset
+	}
+
+	return renderInlineMarkdown(line)
+}
+
+func renderInlineMarkdown(text string) string {
+	// Links go first, since the escape sequences inserted for the other markup contain brackets.
+	text = markdownLinkPattern.ReplaceAllString(text, ansiUnderline+"$1"+ansiReset+" "+ansiMagenta+"($2)"+ansiReset)
+	text = markdownCodePattern.ReplaceAllString(text, ansiCyan+"$1"+ansiReset)
+	text = markdownBoldPattern.ReplaceAllString(text, ansiBold+"$1$2"+ansiReset)
+
+	return text
+}
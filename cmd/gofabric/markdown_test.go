@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdownRenderer(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	renderer := newMarkdownRenderer(&out)
+
+	for _, chunk := range []string{"# Ti", "tle\n- **bold** item\n", "```go\nfunc main", "() {}\n```\nSee `x` and [docs](https://example.com)"} {
+		if err := renderer.Write(chunk); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+	}
+
+	if err := renderer.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	want := ansiBold + ansiUnderline + "Title" + ansiReset + "\n" +
+		"• " + ansiBold + "bold" + ansiReset + " item\n" +
+		ansiDim + "```go" + ansiReset + "\n" +
+		ansiCyan + "func main() {}" + ansiReset + "\n" +
+		ansiDim + "```" + ansiReset + "\n" +
+		"See " + ansiCyan + "x" + ansiReset + " and " +
+		ansiUnderline + "docs" + ansiReset + " " + ansiMagenta + "(https://example.com)" + ansiReset + "\n"
+	if diff := cmp.Diff(want, out.String()); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputYAML  = "yaml"
+)
+
+func isOutputFormat(output string) bool {
+	return output == outputJSON || output == outputTable || output == outputYAML
+}
+
+// table is the tabular representation of a command result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes the result of a command in the configured output format: value is encoded for the
+// json and yaml formats, and t is written for the table format.
+func (a *app) print(value any, t table) error {
+	switch a.output {
+	case outputJSON:
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(value)
+	case outputYAML:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		return writeYAML(a.stdout, data)
+	default:
+		return writeTable(a.stdout, t)
+	}
+}
+
+func writeTable(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if len(t.header) > 0 {
+		if _, err := fmt.Fprintln(tw, strings.Join(t.header, "\t")); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range t.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// Keep every row on a single line so that the columns stay aligned.
+			cells[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "\n", `\n`), "\t", " ")
+		}
+
+		if _, err := fmt.Fprintln(tw, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// yamlNode is a JSON value decoded with the order of the object keys preserved.
+type yamlNode struct {
+	kind   byte // kind is '{' for objects, '[' for arrays, and 0 for scalars.
+	keys   []string
+	values []*yamlNode
+	scalar any
+}
+
+// writeYAML writes the JSON document data as YAML. Object keys keep their JSON order.
+func writeYAML(w io.Writer, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	node, err := decodeYAMLNode(decoder)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case node.kind != 0 && len(node.values) > 0:
+		encodeYAMLNode(&buf, node, 0)
+	default:
+		buf.WriteString(yamlScalar(node, 0))
+		buf.WriteByte('\n')
+	}
+
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func decodeYAMLNode(decoder *json.Decoder) (*yamlNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &yamlNode{scalar: token}, nil
+	}
+
+	node := &yamlNode{kind: byte(delim)}
+	for decoder.More() {
+		if node.kind == '{' {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, errors.New("invalid object key")
+			}
+			node.keys = append(node.keys, key)
+		}
+
+		value, err := decodeYAMLNode(decoder)
+		if err != nil {
+			return nil, err
+		}
+		node.values = append(node.values, value)
+	}
+
+	// Consume the closing delimiter.
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// encodeYAMLNode writes a non-empty object or array at the specified indentation.
+func encodeYAMLNode(buf *bytes.Buffer, node *yamlNode, indent int) {
+	prefix := strings.Repeat("  ", indent)
+
+	for i, value := range node.values {
+		buf.WriteString(prefix)
+		if node.kind == '{' {
+			buf.WriteString(yamlString(node.keys[i]))
+			buf.WriteByte(':')
+		} else {
+			buf.WriteByte('-')
+		}
+
+		switch {
+		case value.kind != 0 && len(value.values) > 0:
+			buf.WriteByte('\n')
+			encodeYAMLNode(buf, value, indent+1)
+		default:
+			buf.WriteByte(' ')
+			buf.WriteString(yamlScalar(value, indent+1))
+			buf.WriteByte('\n')
+		}
+	}
+}
+
+// yamlScalar returns the YAML representation of a scalar or empty collection. Multi-line strings are
+// written as literal blocks indented at the specified level.
+func yamlScalar(node *yamlNode, indent int) string {
+	switch node.kind {
+	case '{':
+		return "{}"
+	case '[':
+		return "[]"
+	}
+
+	switch value := node.scalar.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case string:
+		if strings.Contains(value, "\n") && !strings.HasPrefix(value, " ") && !strings.ContainsAny(value, "\r\t") {
+			return yamlLiteralBlock(value, indent)
+		}
+
+		return yamlString(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func yamlLiteralBlock(value string, indent int) string {
+	header := "|"
+	switch {
+	case strings.HasSuffix(value, "\n\n"):
+		header += "+"
+	case !strings.HasSuffix(value, "\n"):
+		header += "-"
+	}
+
+	prefix := strings.Repeat("  ", indent)
+
+	var b strings.Builder
+	b.WriteString(header)
+	for _, line := range strings.Split(strings.TrimSuffix(value, "\n"), "\n") {
+		b.WriteByte('\n')
+		if line != "" {
+			b.WriteString(prefix)
+			b.WriteString(line)
+		}
+	}
+
+	return b.String()
+}
+
+var (
+	yamlPlainPattern    = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_./ ()@+=,-]*$`)
+	yamlReservedPattern = regexp.MustCompile(`(?i)^(true|false|yes|no|on|off|y|n|null|~|[-+]?(\.inf|\.nan))$`)
+	yamlNumberPattern   = regexp.MustCompile(`^[-+]?(\.[0-9]|[0-9])[0-9_.eExXoObB+-]*$`)
+)
+
+// yamlString returns the string as a plain scalar if it would be read back as the same string, and
+// double-quoted otherwise.
+func yamlString(value string) string {
+	if yamlPlainPattern.MatchString(value) &&
+		!strings.HasSuffix(value, " ") &&
+		!yamlReservedPattern.MatchString(value) &&
+		!yamlNumberPattern.MatchString(value) {
+		return value
+	}
+
+	// A JSON string is a valid YAML double-quoted scalar.
+	quoted, _ := json.Marshal(value)
+
+	return string(quoted)
+}
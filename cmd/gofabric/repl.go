@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+const replHelp = `Commands:
+  /pattern [name]            use a pattern, or none without a name
+  /model [vendor] [model]    use a model, inferring the vendor if omitted, or the default without a model
+  /context [name]            use a context, or none without a name
+  /strategy [name]           use a strategy, or none without a name
+  /temperature [value]       set the temperature, or the default without a value
+  /save <name>               save the conversation to the named session and keep recording it there
+  /reset                     start the conversation over
+  /help                      show this help
+  /exit                      leave, like Ctrl-D
+
+Ctrl-C cancels the response being generated.
+`
+
+// repl is an interactive chat session. Every turn is recorded in a server-side session, which is
+// used as context for the next turn.
+type repl struct {
+	app     *app
+	client  *gofabric.Client
+	catalog *gofabric.ModelCatalog
+	flags   *chatFlags
+
+	// The name of the session recording the conversation
+	session string
+	// Whether the session is deleted when leaving because it was not named by the user
+	temporary bool
+}
+
+// runREPL runs an interactive chat session reading the turns from the standard input.
+func (a *app) runREPL(ctx context.Context, client *gofabric.Client, flags *chatFlags) error {
+	r := &repl{
+		app:     a,
+		client:  client,
+		catalog: gofabric.NewModelCatalog(client, time.Minute),
+		flags:   flags,
+		session: flags.session,
+	}
+
+	if r.session == "" {
+		r.session = fmt.Sprintf("gofabric-%d", time.Now().UnixNano())
+		r.temporary = true
+	} else if session, err := client.GetSessionMetadata(ctx, r.session); err == nil {
+		r.printf("Resuming session %q (%d messages).\n", r.session, len(session.Messages))
+	} else if !errors.Is(err, gofabric.ErrNotFound) {
+		return err
+	}
+
+	r.printf("Type /help for the commands, Ctrl-D to leave.\n")
+
+	defer r.cleanup()
+
+	previous := a.setInterruptHandler(func() {
+		r.printf("\n(use /exit or Ctrl-D to leave)\n> ")
+	})
+	defer a.setInterruptHandler(previous)
+
+	scanner := bufio.NewScanner(a.stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for {
+		r.printf("> ")
+
+		if !scanner.Scan() {
+			r.printf("\n")
+
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "/"):
+			done, err := r.runCommand(ctx, line)
+			if err != nil {
+				r.printf("error: %v\n", err)
+			}
+
+			if done {
+				return nil
+			}
+		default:
+			if err := r.send(ctx, line); err != nil {
+				r.printf("error: %v\n", err)
+			}
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// runCommand runs a slash command and reports whether the session should end.
+func (r *repl) runCommand(ctx context.Context, line string) (bool, error) {
+	fields := strings.Fields(line)
+	command, args := fields[0], fields[1:]
+
+	// Optional single argument of the pattern, context and strategy commands.
+	name := strings.Join(args, " ")
+
+	switch command {
+	case "/pattern":
+		r.flags.pattern = name
+		r.printSetting("pattern", name)
+	case "/context":
+		r.flags.context = name
+		r.printSetting("context", name)
+	case "/strategy":
+		r.flags.strategy = name
+		r.printSetting("strategy", name)
+	case "/model":
+		return false, r.setModel(ctx, args)
+	case "/temperature":
+		return false, r.setTemperature(args)
+	case "/save":
+		return false, r.save(ctx, name)
+	case "/reset":
+		return false, r.reset(ctx)
+	case "/help":
+		r.printf("%s", replHelp)
+	case "/exit", "/quit":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command %s, type /help for the commands", command)
+	}
+
+	return false, nil
+}
+
+func (r *repl) setModel(ctx context.Context, args []string) error {
+	switch len(args) {
+	case 0:
+		r.flags.vendor, r.flags.model = "", ""
+	case 1:
+		vendor, err := r.catalog.InferVendor(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		r.flags.vendor, r.flags.model = vendor, args[0]
+	case 2:
+		if err := r.catalog.Validate(ctx, args[0], args[1]); err != nil {
+			return err
+		}
+
+		r.flags.vendor, r.flags.model = args[0], args[1]
+	default:
+		return errors.New("usage: /model [vendor] [model]")
+	}
+
+	if r.flags.model == "" {
+		r.printSetting("model", "")
+	} else {
+		r.printSetting("model", r.flags.vendor+"/"+r.flags.model)
+	}
+
+	return nil
+}
+
+func (r *repl) setTemperature(args []string) error {
+	switch len(args) {
+	case 0:
+		r.flags.temperature = nil
+		r.printSetting("temperature", "")
+
+		return nil
+	case 1:
+		temperature, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return fmt.Errorf("invalid temperature %q", args[0])
+		}
+
+		// Validate the temperature now rather than on the next turn.
+		if _, err := gofabric.NewChatRequest(
+			gofabric.WithUserInput(args[0]),
+			gofabric.WithTemperature(temperature),
+		); err != nil {
+			return err
+		}
+
+		r.flags.temperature = &temperature
+		r.printSetting("temperature", args[0])
+
+		return nil
+	default:
+		return errors.New("usage: /temperature [value]")
+	}
+}
+
+// save copies the conversation to the named session and records the next turns there.
+func (r *repl) save(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("usage: /save <name>")
+	}
+
+	var messages []gofabric.Message
+
+	session, err := r.client.GetSessionMetadata(ctx, r.session)
+	switch {
+	case err == nil:
+		messages = session.Messages
+	case !errors.Is(err, gofabric.ErrNotFound):
+		return err
+	}
+
+	if name != r.session {
+		if err := r.client.CreateSessionFromMessages(ctx, name, messages); err != nil {
+			return err
+		}
+
+		r.cleanup()
+	}
+
+	r.session = name
+	r.temporary = false
+	r.printf("Saved %d messages to session %q.\n", len(messages), name)
+
+	return nil
+}
+
+// reset deletes the recorded conversation.
+func (r *repl) reset(ctx context.Context) error {
+	if err := r.client.DeleteSession(ctx, r.session); err != nil && !errors.Is(err, gofabric.ErrNotFound) {
+		return err
+	}
+
+	r.printf("Conversation reset.\n")
+
+	return nil
+}
+
+// cleanup deletes the session if it is temporary.
+func (r *repl) cleanup() {
+	if !r.temporary {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_ = r.client.DeleteSession(ctx, r.session)
+}
+
+// send sends a turn and writes the response as it is streamed. Interrupting the command cancels the
+// turn only.
+func (r *repl) send(ctx context.Context, input string) error {
+	chatRequest, err := gofabric.NewChatRequest(
+		append(
+			r.flags.chatRequestOptions(),
+			gofabric.WithUserInput(input),
+			gofabric.WithSessionName(r.session),
+		)...,
+	)
+	if err != nil {
+		return err
+	}
+
+	turnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	previous := r.app.setInterruptHandler(cancel)
+	defer r.app.setInterruptHandler(previous)
+
+	streamResponses, err := r.client.Chat(turnCtx, chatRequest)
+	if err != nil {
+		return err
+	}
+
+	w := newResponseWriter(r.app.stdout, r.app.color)
+
+	var streamErr error
+	for streamResponse := range streamResponses {
+		switch streamResponse.Type {
+		case string(gofabric.StreamResponseTypeContent):
+			if err := w.write(streamResponse.Format, streamResponse.Content); err != nil {
+				return err
+			}
+		case string(gofabric.StreamResponseTypeError):
+			streamErr = streamResponse.Err
+			if streamErr == nil {
+				streamErr = &gofabric.StreamError{Message: streamResponse.Content}
+			}
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	if turnCtx.Err() != nil && ctx.Err() == nil {
+		r.printf("(cancelled)\n")
+
+		return nil
+	}
+
+	return streamErr
+}
+
+func (r *repl) printSetting(name string, value string) {
+	if value == "" {
+		value = "(default)"
+	}
+
+	r.printf("%s: %s\n", name, value)
+}
+
+func (r *repl) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(r.app.stdout, format, args...)
+}
+
+// responseWriter writes streamed content, rendering markdown when writing to a terminal.
+type responseWriter struct {
+	w        io.Writer
+	markdown *markdownRenderer
+
+	// The last content written, to end the response with a newline
+	lastContent string
+}
+
+func newResponseWriter(w io.Writer, color bool) *responseWriter {
+	rw := &responseWriter{w: w}
+	if color {
+		rw.markdown = newMarkdownRenderer(w)
+	}
+
+	return rw
+}
+
+func (rw *responseWriter) write(format string, content string) error {
+	if content == "" {
+		return nil
+	}
+
+	if rw.markdown != nil && format == "markdown" {
+		rw.lastContent = "\n"
+
+		return rw.markdown.Write(content)
+	}
+
+	if rw.markdown != nil {
+		if err := rw.markdown.Flush(); err != nil {
+			return err
+		}
+	}
+
+	rw.lastContent = content
+	_, err := io.WriteString(rw.w, content)
+
+	return err
+}
+
+func (rw *responseWriter) flush() error {
+	if rw.markdown != nil {
+		if err := rw.markdown.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if rw.lastContent != "" && !strings.HasSuffix(rw.lastContent, "\n") {
+		_, err := io.WriteString(rw.w, "\n")
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestREPL(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetModels(gofabric.AvailableModels{
+		Models:  []string{"gpt-4o"},
+		Vendors: map[string][]string{"OpenAI": {"gpt-4o"}},
+	})
+
+	input := strings.Join([]string{
+		"/pattern summarize",
+		"/model gpt-4o",
+		"/temperature 3",
+		"/temperature 0.5",
+		"Hello",
+		"/save review",
+		"Again",
+		"/unknown",
+		"/exit",
+	}, "\n")
+
+	got := runCommand(t, server, input, "chat", "-i")
+	if got.code != 0 {
+		t.Fatalf("Failed to run REPL: %+v", got)
+	}
+
+	for _, want := range []string{
+		"pattern: summarize\n",
+		"model: OpenAI/gpt-4o\n",
+		"error: invalid request: chatOptions.temperature: 3 is out of range [0, 2]\n",
+		"temperature: 0.5\n",
+		"> Hello\n",
+		"Saved 2 messages to session \"review\".\n",
+		"> Again\n",
+		"error: unknown command /unknown",
+	} {
+		if !strings.Contains(got.stdout, want) {
+			t.Fatalf("Expected output to contain %q, got:\n%s", want, got.stdout)
+		}
+	}
+
+	session, ok := server.Session("review")
+	if !ok {
+		t.Fatal("Expected the session to be saved")
+	}
+
+	want := []gofabric.Message{
+		gofabric.UserMessage("Hello"),
+		gofabric.AssistantMessage("Hello"),
+		gofabric.UserMessage("Again"),
+		gofabric.AssistantMessage("Again"),
+	}
+	if diff := cmp.Diff(want, session.Messages); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	chatRequests := server.ChatRequests()
+	if diff := cmp.Diff("review", chatRequests[1].Prompts[0].SessionName); diff != "" {
+		t.Fatalf("Session name mismatch (-want +got):\n%s", diff)
+	}
+
+	prompt := chatRequests[0].Prompts[0]
+	if prompt.PatternName != "summarize" || prompt.Vendor != "OpenAI" || *chatRequests[0].ChatOptions.Temperature != 0.5 {
+		t.Fatalf("Unexpected chat request: %+v", chatRequests[0])
+	}
+
+	sessions, err := server.Client().ListSessions(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"review"}, sessions); diff != "" {
+		t.Fatalf("Expected the temporary session to be deleted (-want +got):\n%s", diff)
+	}
+}
+
+func TestREPLResumeAndReset(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetSession(gofabric.Session{
+		Name:     "review",
+		Messages: []gofabric.Message{gofabric.UserMessage("Hello"), gofabric.AssistantMessage("Hello")},
+	})
+
+	got := runCommand(t, server, "/reset\n", "chat", "-i", "--session", "review")
+	if got.code != 0 {
+		t.Fatalf("Failed to run REPL: %+v", got)
+	}
+
+	if !strings.Contains(got.stdout, "Resuming session \"review\" (2 messages).\n") {
+		t.Fatalf("Unexpected output:\n%s", got.stdout)
+	}
+
+	if _, ok := server.Session("review"); ok {
+		t.Fatal("Expected the session to be reset")
+	}
+}
+
+func TestREPLInterrupt(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	server.SetChunkDelay(20 * time.Millisecond)
+	server.SetChatScript(func(*gofabric.ChatRequest) []gofabric.StreamResponse {
+		responses := []gofabric.StreamResponse{{Type: "content", Format: "plain", Content: "partial"}}
+		for range 100 {
+			responses = append(responses, gofabric.StreamResponse{Type: "content", Format: "plain", Content: "."})
+		}
+
+		return append(responses, gofabric.StreamResponse{Type: "complete", Format: "plain"})
+	})
+
+	stdin, stdinWriter := io.Pipe()
+	var stdout syncBuffer
+	interrupts := make(chan os.Signal, 1)
+	lookupEnv := func(key string) (string, bool) {
+		switch key {
+		case serverURLEnvName:
+			return server.URL, true
+		case apiKeyEnvName:
+			return "secret", true
+		default:
+			return "", false
+		}
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- run(context.Background(), []string{"chat", "-i"}, stdin, &stdout, io.Discard, lookupEnv, interrupts)
+	}()
+
+	waitForOutput := func(want string) {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for !strings.Contains(stdout.String(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %q, got:\n%s", want, stdout.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	_, _ = io.WriteString(stdinWriter, "Hello\n")
+	waitForOutput("partial")
+
+	interrupts <- os.Interrupt
+	waitForOutput("(cancelled)\n")
+
+	interrupts <- os.Interrupt
+	waitForOutput("(use /exit or Ctrl-D to leave)")
+
+	_ = stdinWriter.Close()
+
+	select {
+	case code := <-done:
+		if diff := cmp.Diff(exitOK, code); diff != "" {
+			t.Fatalf("Exit code mismatch (-want +got):\n%s", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the REPL to exit")
+	}
+}
@@ -0,0 +1,338 @@
+package gofabric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ConfigUpdate holds the API keys to change in a Config. Nil fields are left unchanged; set a field
+// to a pointer to the empty string to clear the key.
+//
+// For example, to change the OpenAI key only:
+//
+//	openAI := "sk-..."
+//	err := client.PatchConfig(ctx, gofabric.ConfigUpdate{OpenAI: &openAI}.Apply)
+type ConfigUpdate struct {
+	Anthropic  *string // Anthropic API key.
+	DeepSeek   *string // DeepSeek API key.
+	Gemini     *string // Gemini API key.
+	Grokai     *string // Grokai API key.
+	Groq       *string // Groq API key.
+	LMStudio   *string // LMStudio API key.
+	Mistral    *string // Mistral API key.
+	Ollama     *string // Ollama API key.
+	OpenAI     *string // OpenAI API key.
+	OpenRouter *string // OpenRouter API key.
+	Silicon    *string // Silicon API key.
+
+	// Providers holds the values to set by provider name, including providers that are not fields
+	// of Config, see Config.Set.
+	Providers map[string]string
+}
+
+// Apply sets the keys of config that are set in the update.
+func (u ConfigUpdate) Apply(config *Config) {
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{u.Anthropic, &config.Anthropic},
+		{u.DeepSeek, &config.DeepSeek},
+		{u.Gemini, &config.Gemini},
+		{u.Grokai, &config.Grokai},
+		{u.Groq, &config.Groq},
+		{u.LMStudio, &config.LMStudio},
+		{u.Mistral, &config.Mistral},
+		{u.Ollama, &config.Ollama},
+		{u.OpenAI, &config.OpenAI},
+		{u.OpenRouter, &config.OpenRouter},
+		{u.Silicon, &config.Silicon},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+
+	for provider, value := range u.Providers {
+		config.Set(provider, value)
+	}
+}
+
+// PatchConfig updates the configuration of fabric with a read-modify-write: it gets the current
+// configuration, passes it to modify, and sends the modified configuration back, so that the keys
+// left untouched by modify keep their current value.
+//
+// The server does not support conditional updates, so a concurrent update made between the read and
+// the write is overwritten.
+func (c *Client) PatchConfig(ctx context.Context, modify func(config *Config)) error {
+	config, err := c.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to patch config: %w", err)
+	}
+
+	modify(config)
+
+	if err := c.UpdateConfig(ctx, config); err != nil {
+		return fmt.Errorf("failed to patch config: %w", err)
+	}
+
+	return nil
+}
+
+// String returns the config with the API keys that are set masked, in the format of the %+v verb.
+func (c Config) String() string {
+	var b strings.Builder
+
+	b.WriteString("{")
+	for i, key := range c.maskedKeys() {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+
+		b.WriteString(key.field + ":" + key.value)
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// GoString returns the config with the API keys that are set masked, in the format of the %#v verb.
+func (c Config) GoString() string {
+	var b strings.Builder
+
+	b.WriteString("gofabric.Config{")
+	for i, key := range c.maskedKeys() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		b.WriteString(key.field + ":" + fmt.Sprintf("%q", key.value))
+	}
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// LogValue implements slog.LogValuer, masking the API keys that are set.
+func (c Config) LogValue() slog.Value {
+	keys := c.maskedKeys()
+
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.String(key.name, key.value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// maskedConfigKey is an API key of a Config with its value masked.
+type maskedConfigKey struct {
+	field string // field is the name of the Config field, or the JSON name of an extra field.
+	name  string // name is the JSON name of the key.
+	value string // value is redacted if the key is set, empty otherwise.
+}
+
+// maskedKeys returns the API keys of the config in declaration order, followed by the extra fields
+// in lexical order, with their values masked.
+func (c Config) maskedKeys() []maskedConfigKey {
+	v := reflect.ValueOf(c)
+
+	keys := make([]maskedConfigKey, 0, len(configFields)+len(c.Extra))
+	for _, field := range configFields {
+		value := v.Field(field.index).String()
+		if value != "" {
+			value = redacted
+		}
+
+		keys = append(keys, maskedConfigKey{field: field.field, name: field.name, value: value})
+	}
+
+	for _, name := range c.extraNames() {
+		value := redacted
+		if raw := string(c.Extra[name]); raw == `""` || raw == "null" {
+			value = ""
+		}
+
+		keys = append(keys, maskedConfigKey{field: name, name: name, value: value})
+	}
+
+	return keys
+}
+
+// configField is a field of Config holding the key of a provider.
+type configField struct {
+	index int    // index is the index of the field in Config.
+	field string // field is the name of the field.
+	name  string // name is the JSON name of the field, which is the name of the provider.
+}
+
+// configFields are the fields of Config holding the keys of the providers, in declaration order.
+var configFields = func() []configField {
+	var fields []configField
+
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || field.Type.Kind() != reflect.String || name == "" || name == "-" {
+			continue
+		}
+
+		fields = append(fields, configField{index: i, field: field.Name, name: name})
+	}
+
+	return fields
+}()
+
+// lookupConfigField returns the field of Config holding the key of the provider. Provider names are
+// matched case-insensitively, like JSON field names.
+func lookupConfigField(provider string) (configField, bool) {
+	for _, field := range configFields {
+		if strings.EqualFold(field.name, provider) {
+			return field, true
+		}
+	}
+
+	return configField{}, false
+}
+
+// Get returns the value of the provider, which may be a field of the config or an extra field
+// holding a string. It reports whether the provider is known.
+func (c Config) Get(provider string) (string, bool) {
+	if field, ok := lookupConfigField(provider); ok {
+		return reflect.ValueOf(c).Field(field.index).String(), true
+	}
+
+	var value string
+	if err := json.Unmarshal(c.Extra[provider], &value); err != nil {
+		return "", false
+	}
+
+	return value, true
+}
+
+// Set sets the value of the provider, storing it in Extra if the provider is not a field of the
+// config.
+func (c *Config) Set(provider string, value string) {
+	if field, ok := lookupConfigField(provider); ok {
+		reflect.ValueOf(c).Elem().Field(field.index).SetString(value)
+
+		return
+	}
+
+	data, _ := json.Marshal(value)
+
+	if c.Extra == nil {
+		c.Extra = make(map[string]json.RawMessage)
+	}
+	c.Extra[provider] = data
+}
+
+// Providers returns the values of the config keyed by provider name, including the extra fields
+// holding a string. Modifying the map does not modify the config.
+func (c Config) Providers() map[string]string {
+	providers := make(map[string]string, len(configFields)+len(c.Extra))
+
+	for _, name := range c.extraNames() {
+		if value, ok := c.Get(name); ok {
+			providers[name] = value
+		}
+	}
+
+	v := reflect.ValueOf(c)
+	for _, field := range configFields {
+		providers[field.name] = v.Field(field.index).String()
+	}
+
+	return providers
+}
+
+// extraNames returns the names of the extra fields in lexical order, leaving out those shadowed by a
+// field of the config.
+func (c Config) extraNames() []string {
+	names := make([]string, 0, len(c.Extra))
+	for name := range c.Extra {
+		if _, ok := lookupConfigField(name); !ok {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// MarshalJSON implements json.Marshaler, adding the extra fields after the fields of the config.
+func (c Config) MarshalJSON() ([]byte, error) {
+	// The config type has the fields of Config but not its methods, avoiding infinite recursion.
+	type config Config
+
+	data, err := json.Marshal(config(c))
+	if err != nil {
+		return nil, err
+	}
+
+	names := c.extraNames()
+	if len(names) == 0 {
+		return data, nil
+	}
+
+	var b bytes.Buffer
+	b.Write(data[:len(data)-1])
+
+	for _, name := range names {
+		key, _ := json.Marshal(name)
+
+		value := c.Extra[name]
+		if len(value) == 0 {
+			value = json.RawMessage("null")
+		}
+
+		b.WriteByte(',')
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping the unknown fields in Extra.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+
+	var known config
+	if err := json.Unmarshal(data, &known); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	maps.DeleteFunc(fields, func(name string, _ json.RawMessage) bool {
+		_, ok := lookupConfigField(name)
+
+		return ok
+	})
+
+	known.Extra = nil
+	if len(fields) > 0 {
+		known.Extra = fields
+	}
+
+	*c = Config(known)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package gofabric_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func TestPatchConfig(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetConfig(gofabric.Config{Anthropic: "sk-ant", OpenAI: "sk-old", Ollama: "http://localhost:11434"})
+
+	client := server.Client()
+
+	openAI, ollama := "sk-new", ""
+	update := gofabric.ConfigUpdate{OpenAI: &openAI, Ollama: &ollama}
+
+	if err := client.PatchConfig(context.Background(), update.Apply); err != nil {
+		t.Fatalf("Failed to patch config: %v", err)
+	}
+
+	want := gofabric.Config{Anthropic: "sk-ant", OpenAI: "sk-new"}
+	if diff := cmp.Diff(want, server.Config()); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPatchConfigError(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.InjectFault(gofabrictest.Fault{Path: "/config", StatusCode: 500})
+
+	called := false
+	err := server.Client().PatchConfig(context.Background(), func(*gofabric.Config) { called = true })
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if called {
+		t.Fatal("Expected the config not to be modified when it cannot be read")
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	t.Parallel()
+
+	config := &gofabric.Config{Anthropic: "sk-ant", OpenAI: "sk-openai"}
+
+	for _, verb := range []string{"%v", "%+v", "%s", "%#v"} {
+		got := fmt.Sprintf(verb, config)
+		if strings.Contains(got, "sk-") {
+			t.Errorf("Expected the keys to be masked with %s, got %s", verb, got)
+		}
+	}
+
+	want := "{Anthropic:REDACTED DeepSeek: Gemini: Grokai: Groq: LMStudio: Mistral: Ollama: OpenAI:REDACTED " +
+		"OpenRouter: Silicon:}"
+	if got := config.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestConfigPreservesUnknownFields(t *testing.T) {
+	t.Parallel()
+
+	data := `{"anthropic":"sk-ant","azure":"sk-azure","bedrock":{"region":"us-east-1"}}`
+
+	var config gofabric.Config
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+
+	want := gofabric.Config{
+		Anthropic: "sk-ant",
+		Extra: map[string]json.RawMessage{
+			"azure":   json.RawMessage(`"sk-azure"`),
+			"bedrock": json.RawMessage(`{"region":"us-east-1"}`),
+		},
+	}
+	if diff := cmp.Diff(want, config); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Failed to encode config: %v", err)
+	}
+
+	wantEncoded := `{"anthropic":"sk-ant","deepseek":"","gemini":"","grokai":"","groq":"","lmstudio":"",` +
+		`"mistral":"","ollama":"","openai":"","openrouter":"","silicon":"",` +
+		`"azure":"sk-azure","bedrock":{"region":"us-east-1"}}`
+	if string(encoded) != wantEncoded {
+		t.Fatalf("Expected %s, got %s", wantEncoded, encoded)
+	}
+}
+
+func TestConfigUnknownFieldsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetConfig(gofabric.Config{
+		OpenAI: "sk-old",
+		Extra:  map[string]json.RawMessage{"perplexity": json.RawMessage(`"pplx-key"`)},
+	})
+
+	client := server.Client()
+
+	update := gofabric.ConfigUpdate{Providers: map[string]string{"openai": "sk-new", "azure": "sk-azure"}}
+	if err := client.PatchConfig(context.Background(), update.Apply); err != nil {
+		t.Fatalf("Failed to patch config: %v", err)
+	}
+
+	config, err := client.GetConfig(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	providers := config.Providers()
+	for provider, want := range map[string]string{
+		"openai":     "sk-new",
+		"perplexity": "pplx-key",
+		"azure":      "sk-azure",
+		"anthropic":  "",
+	} {
+		if got, ok := providers[provider]; !ok || got != want {
+			t.Errorf("Expected provider %s to be %q, got %q", provider, want, got)
+		}
+	}
+
+	if got := len(providers); got != 13 {
+		t.Errorf("Expected 13 providers, got %d", got)
+	}
+}
+
+func TestConfigGetSet(t *testing.T) {
+	t.Parallel()
+
+	var config gofabric.Config
+	config.Set("OpenAI", "sk-openai")
+	config.Set("azure", "sk-azure")
+
+	if config.OpenAI != "sk-openai" {
+		t.Errorf("Expected the OpenAI field to be set, got %q", config.OpenAI)
+	}
+
+	for provider, want := range map[string]string{"openai": "sk-openai", "azure": "sk-azure", "groq": ""} {
+		if got, ok := config.Get(provider); !ok || got != want {
+			t.Errorf("Expected %s to be %q, got %q (%v)", provider, want, got, ok)
+		}
+	}
+
+	if _, ok := config.Get("perplexity"); ok {
+		t.Error("Expected perplexity to be unknown")
+	}
+
+	if got := config.String(); strings.Contains(got, "sk-") || !strings.Contains(got, "azure:REDACTED") {
+		t.Errorf("Expected the extra key to be masked, got %s", got)
+	}
+}
@@ -0,0 +1,197 @@
+package gofabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// ConversationConfig holds the settings applied to every turn of a Conversation.
+type ConversationConfig struct {
+	Vendor       string            // Vendor is the name of the LLM vendor (e.g., OpenAI, Anthropic).
+	Model        string            // Model is the name of the model to use.
+	PatternName  string            // PatternName is the name of the pattern to use.
+	ContextName  string            // ContextName is the name of the context to use.
+	StrategyName string            // StrategyName is the name of the strategy to use.
+	Variables    map[string]string // Variables holds the values of the pattern variables.
+	Language     string            // Language specifies the language for the chat.
+	ChatOptions  ChatOptions       // ChatOptions contains various options for the chat.
+}
+
+// Conversation is a multi-turn conversation with Fabric backed by a server-side session.
+//
+// Every turn is sent with the session name of the conversation, so the server records the exchange
+// in the session and uses it as context for the next turn. The Conversation keeps a local copy of
+// the session history, refreshed after every turn.
+//
+// A Conversation is safe for concurrent use, but turns should not be sent concurrently.
+type Conversation struct {
+	client      *Client
+	sessionName string
+	config      ConversationConfig
+
+	mu      sync.Mutex
+	history []Message
+}
+
+// NewConversation creates a Conversation bound to the named session. Call Load to pick up the
+// history of an existing session.
+func NewConversation(client *Client, sessionName string, config ConversationConfig) *Conversation {
+	return &Conversation{
+		client:      client,
+		sessionName: sessionName,
+		config:      config,
+	}
+}
+
+// SessionName returns the name of the session backing the conversation.
+func (c *Conversation) SessionName() string {
+	return c.sessionName
+}
+
+// History returns a copy of the messages exchanged so far.
+func (c *Conversation) History() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return slices.Clone(c.history)
+}
+
+// Turns returns the number of turns exchanged so far, i.e. the number of user messages.
+func (c *Conversation) Turns() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	turns := 0
+	for _, message := range c.history {
+		if message.Role == MessageRoleUser {
+			turns++
+		}
+	}
+
+	return turns
+}
+
+// Load replaces the local history with the messages of the server-side session. A session that
+// does not exist yet results in an empty history.
+func (c *Conversation) Load(ctx context.Context) error {
+	session, err := c.client.GetSessionMetadata(ctx, c.sessionName)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	var history []Message
+	if session != nil {
+		history = session.Messages
+	}
+
+	c.mu.Lock()
+	c.history = history
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Send sends the next turn of the conversation and returns an iterator over the streamed responses,
+// with the same semantics as Client.ChatStream.
+//
+// Once the stream completes, the local history is refreshed from the server-side session. An error
+// refreshing it is yielded after the "complete" response.
+func (c *Conversation) Send(ctx context.Context, input string) iter.Seq2[StreamResponse, error] {
+	chatRequest := &ChatRequest{
+		Prompts: []PromptRequest{
+			{
+				UserInput:    input,
+				Vendor:       c.config.Vendor,
+				Model:        c.config.Model,
+				ContextName:  c.config.ContextName,
+				PatternName:  c.config.PatternName,
+				StrategyName: c.config.StrategyName,
+				SessionName:  c.sessionName,
+				Variables:    c.config.Variables,
+			},
+		},
+		Language:    c.config.Language,
+		ChatOptions: c.config.ChatOptions,
+	}
+
+	return func(yield func(StreamResponse, error) bool) {
+		completed := false
+
+		for streamResponse, err := range c.client.ChatStream(ctx, chatRequest) {
+			if !yield(streamResponse, err) {
+				return
+			}
+
+			completed = err == nil && streamResponse.Type == string(StreamResponseTypeComplete)
+		}
+
+		if !completed {
+			return
+		}
+
+		if err := c.Load(ctx); err != nil {
+			yield(StreamResponse{}, fmt.Errorf("failed to refresh conversation history: %w", err))
+		}
+	}
+}
+
+// Fork copies the conversation into a new session and returns a Conversation bound to it with the
+// same configuration. To branch off an earlier turn, Rewind the returned Conversation.
+func (c *Conversation) Fork(ctx context.Context, sessionName string) (*Conversation, error) {
+	history := c.History()
+
+	if err := c.client.CreateSessionFromMessages(ctx, sessionName, history); err != nil {
+		return nil, fmt.Errorf("failed to fork conversation: %w", err)
+	}
+
+	fork := NewConversation(c.client, sessionName, c.config)
+	fork.history = history
+
+	return fork, nil
+}
+
+// Rewind discards every turn after the first turns turns, both locally and in the server-side
+// session. Messages preceding the first turn, such as the system message, are kept.
+func (c *Conversation) Rewind(ctx context.Context, turns int) error {
+	if turns < 0 {
+		return fmt.Errorf("failed to rewind conversation: invalid number of turns %d", turns)
+	}
+
+	c.mu.Lock()
+	history := c.history
+	c.mu.Unlock()
+
+	end := len(history)
+	seen := 0
+	for i, message := range history {
+		if message.Role != MessageRoleUser {
+			continue
+		}
+
+		if seen == turns {
+			end = i
+			break
+		}
+		seen++
+	}
+
+	if seen < turns {
+		return fmt.Errorf("failed to rewind conversation: only %d turns exchanged", seen)
+	}
+
+	history = slices.Clone(history[:end])
+
+	if err := c.client.CreateSessionFromMessages(ctx, c.sessionName, history); err != nil {
+		return fmt.Errorf("failed to rewind conversation: %w", err)
+	}
+
+	c.mu.Lock()
+	c.history = history
+	c.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package gofabric_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func sendTurn(t *testing.T, conversation *gofabric.Conversation, input string) {
+	t.Helper()
+
+	for _, err := range conversation.Send(context.Background(), input) {
+		if err != nil {
+			t.Fatalf("Failed to send %q: %v", input, err)
+		}
+	}
+}
+
+func TestConversation(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetSession(gofabric.Session{
+		Name:     "chat",
+		Messages: []gofabric.Message{gofabric.SystemMessage("Be terse.")},
+	})
+
+	conversation := gofabric.NewConversation(server.Client(), "chat", gofabric.ConversationConfig{
+		Vendor:      "OpenAI",
+		Model:       "gpt-4o",
+		PatternName: "ai",
+	})
+	if err := conversation.Load(context.Background()); err != nil {
+		t.Fatalf("Failed to load conversation: %v", err)
+	}
+
+	sendTurn(t, conversation, "one")
+	sendTurn(t, conversation, "two")
+
+	want := []gofabric.Message{
+		gofabric.SystemMessage("Be terse."),
+		gofabric.UserMessage("one"),
+		gofabric.AssistantMessage("one"),
+		gofabric.UserMessage("two"),
+		gofabric.AssistantMessage("two"),
+	}
+	if diff := cmp.Diff(want, conversation.History()); diff != "" {
+		t.Fatalf("History mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff(2, conversation.Turns()); diff != "" {
+		t.Fatalf("Turns mismatch (-want +got):\n%s", diff)
+	}
+
+	wantPrompt := gofabric.PromptRequest{
+		UserInput:   "two",
+		Vendor:      "OpenAI",
+		Model:       "gpt-4o",
+		PatternName: "ai",
+		SessionName: "chat",
+	}
+	requests := server.ChatRequests()
+	if diff := cmp.Diff(wantPrompt, requests[len(requests)-1].Prompts[0]); diff != "" {
+		t.Fatalf("Prompt mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConversationForkAndRewind(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+
+	conversation := gofabric.NewConversation(server.Client(), "chat", gofabric.ConversationConfig{})
+	sendTurn(t, conversation, "one")
+	sendTurn(t, conversation, "two")
+
+	fork, err := conversation.Fork(ctx, "branch")
+	if err != nil {
+		t.Fatalf("Failed to fork conversation: %v", err)
+	}
+
+	if err := fork.Rewind(ctx, 1); err != nil {
+		t.Fatalf("Failed to rewind conversation: %v", err)
+	}
+
+	sendTurn(t, fork, "three")
+
+	wantFork := []gofabric.Message{
+		gofabric.UserMessage("one"),
+		gofabric.AssistantMessage("one"),
+		gofabric.UserMessage("three"),
+		gofabric.AssistantMessage("three"),
+	}
+	if diff := cmp.Diff(wantFork, fork.History()); diff != "" {
+		t.Fatalf("Fork history mismatch (-want +got):\n%s", diff)
+	}
+
+	original, _ := server.Session("chat")
+	if diff := cmp.Diff(conversation.History(), original.Messages); diff != "" {
+		t.Fatalf("Original session mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := conversation.Rewind(ctx, 3); err == nil {
+		t.Fatal("Expected an error rewinding past the last turn")
+	}
+}
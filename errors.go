@@ -1,7 +1,39 @@
 package gofabric
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors that can be matched using errors.Is against the errors returned by the Client.
+var (
+	// ErrNotFound is matched by an *HTTPError with status code 404.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is matched by an *HTTPError with status code 401 or 403.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrConflict is matched by an *HTTPError with status code 409.
+	ErrConflict = errors.New("conflict")
+	// ErrServerUnavailable is matched by an *HTTPError with status code 502, 503 or 504.
+	ErrServerUnavailable = errors.New("server unavailable")
+	// ErrDecode is matched by errors caused by a response body that could not be decoded.
+	ErrDecode = errors.New("failed to decode response")
+	// ErrInvalidRequest is matched by a *ValidationError.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrUnknownModel is matched by an *UnknownModelError.
+	ErrUnknownModel = errors.New("unknown model")
+	// ErrAmbiguousModel is matched by errors caused by a model offered by several vendors.
+	ErrAmbiguousModel = errors.New("ambiguous model")
+	// ErrInvalidName is matched by an *InvalidNameError.
+	ErrInvalidName = errors.New("invalid name")
+	// ErrMissingVariables is matched by a *MissingVariablesError.
+	ErrMissingVariables = errors.New("missing pattern variables")
+	// ErrStreamInterrupted is matched by a *StreamInterruptedError.
+	ErrStreamInterrupted = errors.New("chat stream interrupted")
+	// ErrStreamNotResumed is matched by the error of a *StreamInterruptedError when the server
+	// started a new stream instead of resuming the interrupted one.
+	ErrStreamNotResumed = errors.New("chat stream not resumed")
 )
 
 // HTTPError represents an error returned by the Fabric API
@@ -9,6 +41,9 @@ type HTTPError struct {
 	URL        string
 	StatusCode int
 	Body       *string
+
+	// response is the response that caused the error. Its body has already been consumed.
+	response *http.Response
 }
 
 // Error implements the error interface
@@ -23,3 +58,174 @@ func (e *HTTPError) Error() string {
 		*e.Body,
 	)
 }
+
+// Is reports whether target is the sentinel error corresponding to the status code
+func (e *HTTPError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return target == ErrUnauthorized
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrServerUnavailable
+	default:
+		return false
+	}
+}
+
+// EntityError records an error and the entity operation that caused it
+type EntityError struct {
+	Op         string     // Op is the operation: "create", "delete", "exists", "get", "list" or "rename".
+	EntityType EntityType // EntityType is the type of the entity.
+	Name       string     // Name is the name of the entity, empty for "list".
+	NewName    string     // NewName is the new name of the entity for "rename".
+	Err        error      // Err is the underlying error.
+}
+
+// Error implements the error interface
+func (e *EntityError) Error() string {
+	switch e.Op {
+	case "exists":
+		return fmt.Sprintf("failed to check if %s `%s` exists: %v", e.EntityType, e.Name, e.Err)
+	case "list":
+		return fmt.Sprintf("failed to list %ss: %v", e.EntityType, e.Err)
+	case "rename":
+		return fmt.Sprintf("failed to rename %s `%s` to `%s`: %v", e.EntityType, e.Name, e.NewName, e.Err)
+	default:
+		return fmt.Sprintf("failed to %s %s `%s`: %v", e.Op, e.EntityType, e.Name, e.Err)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *EntityError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidNameError is returned when the name of an entity cannot be stored by Fabric, before any
+// request is sent
+type InvalidNameError struct {
+	EntityType EntityType // EntityType is the type of the entity.
+	Name       string     // Name is the invalid name.
+	Reason     string     // Reason describes why the name is invalid.
+}
+
+// Error implements the error interface
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("%s for %s `%s`: %s", ErrInvalidName, e.EntityType, e.Name, e.Reason)
+}
+
+// Is reports whether target is ErrInvalidName
+func (e *InvalidNameError) Is(target error) bool {
+	return target == ErrInvalidName
+}
+
+// MissingVariablesError is returned when rendering a pattern without a value for all its variables
+type MissingVariablesError struct {
+	Pattern string   // Pattern is the name of the pattern.
+	Names   []string // Names lists the variables without a value, in order of first appearance.
+}
+
+// Error implements the error interface
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("%s for pattern `%s`: %s", ErrMissingVariables, e.Pattern, strings.Join(e.Names, ", "))
+}
+
+// Is reports whether target is ErrMissingVariables
+func (e *MissingVariablesError) Is(target error) bool {
+	return target == ErrMissingVariables
+}
+
+// StreamError is returned when the server reports an error in a chat stream.
+type StreamError struct {
+	Message string // Message is the content of the error response sent by the server.
+}
+
+// Error implements the error interface
+func (e *StreamError) Error() string {
+	return "chat stream error: " + e.Message
+}
+
+// StreamInterruptedError is returned when a chat stream ends before the server has sent the
+// "complete" message and cannot be resumed. Callers can use the partial content to decide
+// whether to issue the prompt again.
+type StreamInterruptedError struct {
+	Content     string // Content is the content received before the stream was interrupted.
+	LastEventID string // LastEventID is the ID of the last SSE event received, if any.
+	Err         error  // Err is the error that interrupted the stream, nil if it ended prematurely.
+}
+
+// Error implements the error interface
+func (e *StreamInterruptedError) Error() string {
+	if e.Err == nil {
+		return ErrStreamInterrupted.Error() + ": stream ended before completion"
+	}
+	return ErrStreamInterrupted.Error() + ": " + e.Err.Error()
+}
+
+// Is reports whether target is ErrStreamInterrupted
+func (e *StreamInterruptedError) Is(target error) bool {
+	return target == ErrStreamInterrupted
+}
+
+// Unwrap returns the error that interrupted the stream
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned when a request fails client-side validation
+type ValidationError struct {
+	Field   string // Field is the path of the invalid field, e.g. "chatOptions.temperature".
+	Message string // Message describes the problem.
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidRequest, e.Field, e.Message)
+}
+
+// Is reports whether target is ErrInvalidRequest
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// UnknownModelError is returned when a vendor/model pair is not available on the Fabric server
+type UnknownModelError struct {
+	VendorModel VendorModel   // VendorModel is the requested pair. Vendor is empty when inferring it.
+	Suggestions []VendorModel // Suggestions lists the closest available models, closest first.
+}
+
+// Error implements the error interface
+func (e *UnknownModelError) Error() string {
+	var b strings.Builder
+	if e.VendorModel.Vendor == "" {
+		fmt.Fprintf(&b, "%s %q", ErrUnknownModel, e.VendorModel.Model)
+	} else {
+		fmt.Fprintf(&b, "%s %q for vendor %q", ErrUnknownModel, e.VendorModel.Model, e.VendorModel.Vendor)
+	}
+
+	for i, suggestion := range e.Suggestions {
+		if i == 0 {
+			b.WriteString("; did you mean ")
+		} else {
+			b.WriteString(" or ")
+		}
+		fmt.Fprintf(&b, "%q", suggestion.String())
+	}
+
+	if len(e.Suggestions) > 0 {
+		b.WriteString("?")
+	}
+
+	return b.String()
+}
+
+// Is reports whether target is ErrUnknownModel
+func (e *UnknownModelError) Is(target error) bool {
+	return target == ErrUnknownModel
+}
+
+func decodeError(err error) error {
+	return fmt.Errorf("%w: %w", ErrDecode, err)
+}
@@ -0,0 +1,156 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+func TestHTTPErrorIs(t *testing.T) {
+	t.Parallel()
+
+	sentinels := []error{
+		gofabric.ErrNotFound,
+		gofabric.ErrUnauthorized,
+		gofabric.ErrConflict,
+		gofabric.ErrServerUnavailable,
+	}
+
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{statusCode: http.StatusNotFound, want: gofabric.ErrNotFound},
+		{statusCode: http.StatusUnauthorized, want: gofabric.ErrUnauthorized},
+		{statusCode: http.StatusForbidden, want: gofabric.ErrUnauthorized},
+		{statusCode: http.StatusConflict, want: gofabric.ErrConflict},
+		{statusCode: http.StatusBadGateway, want: gofabric.ErrServerUnavailable},
+		{statusCode: http.StatusServiceUnavailable, want: gofabric.ErrServerUnavailable},
+		{statusCode: http.StatusGatewayTimeout, want: gofabric.ErrServerUnavailable},
+		{statusCode: http.StatusBadRequest, want: nil},
+		{statusCode: http.StatusInternalServerError, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			t.Parallel()
+
+			err := &gofabric.HTTPError{URL: "http://localhost", StatusCode: tt.statusCode}
+			for _, sentinel := range sentinels {
+				if got, want := errors.Is(err, sentinel), sentinel == tt.want; got != want {
+					t.Fatalf("errors.Is(%d, %v) = %v, want %v", tt.statusCode, sentinel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestEntityErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		call       func(*gofabric.Client) error
+		wantErr    *gofabric.EntityError
+		wantIs     error
+		wantPrefix string
+	}{
+		{
+			name:       "get pattern",
+			statusCode: http.StatusNotFound,
+			call: func(c *gofabric.Client) error {
+				_, err := c.GetPatternMetadata(context.Background(), "test")
+				return err
+			},
+			wantErr:    &gofabric.EntityError{Op: "get", EntityType: gofabric.EntityTypePattern, Name: "test"},
+			wantIs:     gofabric.ErrNotFound,
+			wantPrefix: "failed to get pattern `test`: ",
+		},
+		{
+			name:       "delete session",
+			statusCode: http.StatusUnauthorized,
+			call: func(c *gofabric.Client) error {
+				return c.DeleteSession(context.Background(), "test")
+			},
+			wantErr:    &gofabric.EntityError{Op: "delete", EntityType: gofabric.EntityTypeSession, Name: "test"},
+			wantIs:     gofabric.ErrUnauthorized,
+			wantPrefix: "failed to delete session `test`: ",
+		},
+		{
+			name:       "rename context",
+			statusCode: http.StatusConflict,
+			call: func(c *gofabric.Client) error {
+				return c.RenameContext(context.Background(), "test", "test-2")
+			},
+			wantErr: &gofabric.EntityError{
+				Op:         "rename",
+				EntityType: gofabric.EntityTypeContext,
+				Name:       "test",
+				NewName:    "test-2",
+			},
+			wantIs:     gofabric.ErrConflict,
+			wantPrefix: "failed to rename context `test` to `test-2`: ",
+		},
+		{
+			name:       "list patterns",
+			statusCode: http.StatusServiceUnavailable,
+			call: func(c *gofabric.Client) error {
+				_, err := c.ListPatterns(context.Background())
+				return err
+			},
+			wantErr:    &gofabric.EntityError{Op: "list", EntityType: gofabric.EntityTypePattern},
+			wantIs:     gofabric.ErrServerUnavailable,
+			wantPrefix: "failed to list patterns: ",
+		},
+		{
+			name:       "session exists decode",
+			statusCode: http.StatusOK,
+			call: func(c *gofabric.Client) error {
+				_, err := c.SessionExists(context.Background(), "test")
+				return err
+			},
+			wantErr:    &gofabric.EntityError{Op: "exists", EntityType: gofabric.EntityTypeSession, Name: "test"},
+			wantIs:     gofabric.ErrDecode,
+			wantPrefix: "failed to check if session `test` exists: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte("not json"))
+			}))
+			defer ts.Close()
+
+			err := tt.call(gofabric.NewClient(ts.URL))
+			if !errors.Is(err, tt.wantIs) {
+				t.Fatalf("Expected %v, got: %v", tt.wantIs, err)
+			}
+
+			var entityErr *gofabric.EntityError
+			if !errors.As(err, &entityErr) {
+				t.Fatalf("Expected *EntityError, got: %T", err)
+			}
+
+			got := *entityErr
+			got.Err = nil
+			if diff := cmp.Diff(*tt.wantErr, got); diff != "" {
+				t.Fatalf("Mismatch (-want +got):\n%s", diff)
+			}
+
+			if !strings.HasPrefix(err.Error(), tt.wantPrefix) {
+				t.Fatalf("Expected message starting with %q, got: %q", tt.wantPrefix, err.Error())
+			}
+		})
+	}
+}
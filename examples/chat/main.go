@@ -18,18 +18,15 @@ func main() {
 	ctx := context.Background()
 
 	// Prepare chat request
-	chatRequest := &gofabric.ChatRequest{
-		Prompts: []gofabric.PromptRequest{
-			{
-				UserInput:   "Write a Golang function that calculates the factorial of a number.",
-				Vendor:      "Gemini",
-				Model:       "gemini-2.0-flash",
-				ContextName: "",
-				PatternName: "coding_master",
-			},
-		},
-		Language:    "en",
-		ChatOptions: gofabric.ChatOptions{},
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithUserInput("Write a Golang function that calculates the factorial of a number."),
+		gofabric.WithModel("Gemini", "gemini-2.0-flash"),
+		gofabric.WithPattern("coding_master"),
+		gofabric.WithLanguage("en"),
+		gofabric.WithTemperature(0.2),
+	)
+	if err != nil {
+		log.Fatalf("Error building chat request: %v", err)
 	}
 
 	// Start streaming chat
@@ -4,7 +4,6 @@ import (
 	"context"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/sherif-fanous/gofabric"
 )
@@ -30,7 +29,9 @@ func main() {
 	}
 
 	// Create a new session
-	err := client.CreateSession(ctx, sessionName, strings.NewReader("[]"))
+	err := client.CreateSessionFromMessages(ctx, sessionName, []gofabric.Message{
+		gofabric.SystemMessage("You are a helpful assistant."),
+	})
 	if err != nil {
 		log.Fatalf("Error creating session: %v\n", err)
 	}
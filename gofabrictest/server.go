@@ -0,0 +1,663 @@
+// Package gofabrictest provides an in-memory Fabric API server for testing code built on
+// gofabric.Client without a real Fabric instance.
+package gofabrictest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sherif-fanous/gofabric"
+)
+
+const apiKeyHeaderName = "X-API-Key"
+
+// ChatScript returns the responses streamed by the /chat endpoint for a chat request.
+type ChatScript func(chatRequest *gofabric.ChatRequest) []gofabric.StreamResponse
+
+// Fault describes an error injected into the responses of the server.
+type Fault struct {
+	Method     string // Method is the HTTP method to match, empty to match any method.
+	Path       string // Path is the URL path to match, empty to match any path.
+	StatusCode int    // StatusCode is the status code of the response.
+	Body       string // Body is the body of the response.
+	Count      int    // Count is the number of requests to fail, 0 to fail every matching request.
+}
+
+// Disconnect describes a chat stream dropped by the server before it completes.
+type Disconnect struct {
+	After int // After is the number of responses streamed before the connection is dropped.
+	Count int // Count is the number of streams to drop, 0 to drop every stream.
+}
+
+// Server is an in-memory Fabric API server. It implements the /patterns, /contexts, /sessions,
+// /config, /models/names, /strategies and /chat endpoints used by gofabric.Client.
+//
+// A Server is safe for concurrent use. It must be closed with Close when no longer needed.
+type Server struct {
+	// URL is the base URL of the server, of the form http://ipaddr:port with no trailing slash.
+	URL string
+
+	server *httptest.Server
+
+	apiKey string
+
+	mu           sync.Mutex
+	contexts     *store[gofabric.Context]
+	patterns     *store[gofabric.Pattern]
+	sessions     *store[gofabric.Session]
+	config       gofabric.Config
+	models       gofabric.AvailableModels
+	strategies   []gofabric.Strategy
+	latency      time.Duration
+	chunkDelay   time.Duration
+	faults       []Fault
+	disconnects  []Disconnect
+	chatScript   ChatScript
+	chatRequests []gofabric.ChatRequest
+
+	ignoreLastEventID bool
+}
+
+// Option represents a function that configures the Server using the functional options pattern.
+type Option func(*Server)
+
+// NewServer starts and returns a new Server.
+//
+// By default the server accepts every request and its /chat endpoint echoes the user input of the
+// prompts. Use SetChatScript or SetChatResponses to script the stream. Like Fabric, the /chat
+// endpoint records the user input and the streamed content in the session named by each prompt.
+//
+// The responses streamed by the /chat endpoint carry their 1-based position in the script as SSE
+// event ID. A request with a Last-Event-ID header resumes the stream after that response, running
+// the script again, so that dropped streams (see InjectDisconnect) can be resumed.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		contexts: newStore(
+			func(name string, body []byte) (gofabric.Context, error) {
+				return gofabric.Context{Name: name, Content: string(body)}, nil
+			},
+			func(context *gofabric.Context, name string) { context.Name = name },
+		),
+		patterns: newStore(
+			func(name string, body []byte) (gofabric.Pattern, error) {
+				return gofabric.Pattern{Name: name, Pattern: string(body)}, nil
+			},
+			func(pattern *gofabric.Pattern, name string) { pattern.Name = name },
+		),
+		sessions: newStore(
+			func(name string, body []byte) (gofabric.Session, error) {
+				session := gofabric.Session{Name: name}
+				err := json.Unmarshal(body, &session.Messages)
+
+				return session, err
+			},
+			func(session *gofabric.Session, name string) { session.Name = name },
+		),
+		models:     gofabric.AvailableModels{Models: []string{}, Vendors: map[string][]string{}},
+		strategies: []gofabric.Strategy{},
+		chatScript: echoChatScript,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.server = httptest.NewServer(s.handler())
+	s.URL = s.server.URL
+
+	return s
+}
+
+// WithAPIKey makes the server reject requests that do not carry the API key in the X-API-Key
+// header with a 401 status code.
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// Client returns a gofabric.Client configured to talk to the server. When the server requires an
+// API key, the client is configured to send it.
+func (s *Server) Client(opts ...gofabric.Option) *gofabric.Client {
+	if s.apiKey != "" {
+		opts = append([]gofabric.Option{gofabric.WithAPIKey(s.apiKey)}, opts...)
+	}
+
+	return gofabric.NewClient(s.URL, opts...)
+}
+
+// Close shuts down the server and blocks until all outstanding requests have completed.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetContext creates or replaces a context.
+func (s *Server) SetContext(context gofabric.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.contexts.entities[context.Name] = context
+}
+
+// SetPattern creates or replaces a pattern.
+func (s *Server) SetPattern(pattern gofabric.Pattern) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.patterns.entities[pattern.Name] = pattern
+}
+
+// SetSession creates or replaces a session.
+func (s *Server) SetSession(session gofabric.Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions.entities[session.Name] = session
+}
+
+// SetConfig replaces the configuration.
+func (s *Server) SetConfig(config gofabric.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.config = config
+}
+
+// SetModels replaces the available models.
+func (s *Server) SetModels(models gofabric.AvailableModels) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.models = models
+}
+
+// SetStrategies replaces the available strategies.
+func (s *Server) SetStrategies(strategies ...gofabric.Strategy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.strategies = strategies
+}
+
+// Context returns a context and whether it exists.
+func (s *Server) Context(name string) (gofabric.Context, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	context, ok := s.contexts.entities[name]
+
+	return context, ok
+}
+
+// Pattern returns a pattern and whether it exists.
+func (s *Server) Pattern(name string) (gofabric.Pattern, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pattern, ok := s.patterns.entities[name]
+
+	return pattern, ok
+}
+
+// Session returns a session and whether it exists.
+func (s *Server) Session(name string) (gofabric.Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.entities[name]
+
+	return session, ok
+}
+
+// Config returns the configuration.
+func (s *Server) Config() gofabric.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.config
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// InjectFault makes the requests matching the fault fail. Faults are matched in the order they were
+// injected.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, fault)
+}
+
+// ClearFaults removes all the injected faults and disconnects.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+	s.disconnects = nil
+}
+
+// InjectDisconnect makes the /chat endpoint drop the connection of the next chat streams, new or
+// resumed, after streaming some responses. Disconnects are matched in the order they were injected.
+func (s *Server) InjectDisconnect(disconnect Disconnect) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.disconnects = append(s.disconnects, disconnect)
+}
+
+// SetIgnoreLastEventID makes the /chat endpoint ignore the Last-Event-ID header and start the
+// stream over, like servers that cannot resume streams.
+func (s *Server) SetIgnoreLastEventID(ignore bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ignoreLastEventID = ignore
+}
+
+// SetChatScript sets the script producing the responses streamed by the /chat endpoint.
+func (s *Server) SetChatScript(script ChatScript) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chatScript = script
+}
+
+// SetChatResponses makes the /chat endpoint stream the given responses for every request.
+func (s *Server) SetChatResponses(responses ...gofabric.StreamResponse) {
+	s.SetChatScript(func(*gofabric.ChatRequest) []gofabric.StreamResponse {
+		return responses
+	})
+}
+
+// SetChunkDelay delays every response streamed by the /chat endpoint by d.
+func (s *Server) SetChunkDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chunkDelay = d
+}
+
+// ChatRequests returns the chat requests received by the /chat endpoint, in order.
+func (s *Server) ChatRequests() []gofabric.ChatRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.chatRequests)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	registerEntity(s, mux, "/contexts", s.contexts)
+	registerEntity(s, mux, "/patterns", s.patterns)
+	registerEntity(s, mux, "/sessions", s.sessions)
+
+	mux.HandleFunc("GET /config", s.handleGetConfig)
+	mux.HandleFunc("PUT /config/update", s.handleUpdateConfig)
+	mux.HandleFunc("GET /models/names", s.handleListModels)
+	mux.HandleFunc("GET /strategies", s.handleListStrategies)
+	mux.HandleFunc("POST /chat", s.handleChat)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		latency := s.latency
+		s.mu.Unlock()
+
+		if !wait(r, latency) {
+			return
+		}
+
+		if s.apiKey != "" && r.Header.Get(apiKeyHeaderName) != s.apiKey {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+
+			return
+		}
+
+		s.mu.Lock()
+		fault, faulted := s.matchFault(r)
+		s.mu.Unlock()
+
+		if faulted {
+			w.WriteHeader(fault.StatusCode)
+			_, _ = io.WriteString(w, fault.Body)
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// matchFault returns the first fault matching r. s.mu must be held.
+func (s *Server) matchFault(r *http.Request) (Fault, bool) {
+	for i, fault := range s.faults {
+		if (fault.Method != "" && fault.Method != r.Method) || (fault.Path != "" && fault.Path != r.URL.Path) {
+			continue
+		}
+
+		if fault.Count > 0 {
+			if s.faults[i].Count--; s.faults[i].Count == 0 {
+				s.faults = slices.Delete(s.faults, i, i+1)
+			}
+		}
+
+		return fault, true
+	}
+
+	return Fault{}, false
+}
+
+// nextDisconnect returns the number of responses to stream before dropping the next chat stream,
+// and whether it must be dropped. s.mu must be held.
+func (s *Server) nextDisconnect() (int, bool) {
+	if len(s.disconnects) == 0 {
+		return 0, false
+	}
+
+	disconnect := s.disconnects[0]
+	if disconnect.Count > 0 {
+		if s.disconnects[0].Count--; s.disconnects[0].Count == 0 {
+			s.disconnects = s.disconnects[1:]
+		}
+	}
+
+	return disconnect.After, true
+}
+
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.Config())
+}
+
+func (s *Server) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
+	var config gofabric.Config
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.SetConfig(config)
+	writeJSON(w, map[string]string{"status": "success"})
+}
+
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	models := s.models
+	s.mu.Unlock()
+
+	writeJSON(w, models)
+}
+
+func (s *Server) handleListStrategies(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	strategies := s.strategies
+	s.mu.Unlock()
+
+	writeJSON(w, strategies)
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var chatRequest gofabric.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&chatRequest); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.mu.Lock()
+	s.chatRequests = append(s.chatRequests, chatRequest)
+	script := s.chatScript
+	chunkDelay := s.chunkDelay
+	disconnectAfter, disconnected := s.nextDisconnect()
+	resume := !s.ignoreLastEventID
+	s.mu.Unlock()
+
+	streamResponses := script(&chatRequest)
+
+	var start int
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" && resume {
+		n, err := strconv.Atoi(lastEventID)
+		if err != nil || n < 1 || n > len(streamResponses) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid Last-Event-ID %q", lastEventID))
+
+			return
+		}
+
+		start = n
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	var content strings.Builder
+
+	for i, streamResponse := range streamResponses {
+		// The content of a resumed stream includes the responses streamed before it was interrupted.
+		if streamResponse.Type == string(gofabric.StreamResponseTypeContent) {
+			content.WriteString(streamResponse.Content)
+		}
+
+		if i < start {
+			continue
+		}
+
+		if disconnected && i-start == disconnectAfter {
+			panic(http.ErrAbortHandler)
+		}
+
+		if !wait(r, chunkDelay) {
+			return
+		}
+
+		data, err := json.Marshal(streamResponse)
+		if err != nil {
+			return
+		}
+
+		if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", i+1, data); err != nil {
+			return
+		}
+		w.(http.Flusher).Flush()
+	}
+
+	s.recordExchange(&chatRequest, content.String())
+}
+
+// recordExchange appends the user input and the streamed content to the sessions named in the
+// prompts, like Fabric does.
+func (s *Server) recordExchange(chatRequest *gofabric.ChatRequest, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, prompt := range chatRequest.Prompts {
+		if prompt.SessionName == "" {
+			continue
+		}
+
+		session := s.sessions.entities[prompt.SessionName]
+		session.Name = prompt.SessionName
+		session.Messages = append(
+			slices.Clip(session.Messages),
+			gofabric.UserMessage(prompt.UserInput),
+			gofabric.AssistantMessage(content),
+		)
+		s.sessions.entities[prompt.SessionName] = session
+	}
+}
+
+// store holds the entities of one type. Its entities must only be accessed with Server.mu held.
+type store[T any] struct {
+	entities  map[string]T
+	newEntity func(name string, body []byte) (T, error)
+	setName   func(entity *T, name string)
+}
+
+func newStore[T any](
+	newEntity func(name string, body []byte) (T, error),
+	setName func(entity *T, name string),
+) *store[T] {
+	return &store[T]{entities: make(map[string]T), newEntity: newEntity, setName: setName}
+}
+
+// registerEntity registers the entity endpoints rooted at prefix, backed by st.
+func registerEntity[T any](s *Server, mux *http.ServeMux, prefix string, st *store[T]) {
+	mux.HandleFunc("GET "+prefix+"/names", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		names := slices.Sorted(maps.Keys(st.entities))
+		s.mu.Unlock()
+
+		writeJSON(w, names)
+	})
+
+	mux.HandleFunc("GET "+prefix+"/exists/{name}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		_, exists := st.entities[r.PathValue("name")]
+		s.mu.Unlock()
+
+		writeJSON(w, exists)
+	})
+
+	mux.HandleFunc("GET "+prefix+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		s.mu.Lock()
+		entity, ok := st.entities[name]
+		s.mu.Unlock()
+
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", name))
+
+			return
+		}
+
+		writeJSON(w, entity)
+	})
+
+	mux.HandleFunc("POST "+prefix+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		entity, err := st.newEntity(name, body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		s.mu.Lock()
+		st.entities[name] = entity
+		s.mu.Unlock()
+
+		writeJSON(w, map[string]string{"status": "success"})
+	})
+
+	mux.HandleFunc("PUT "+prefix+"/rename/{oldName}/{newName}", func(w http.ResponseWriter, r *http.Request) {
+		oldName := r.PathValue("oldName")
+		newName := r.PathValue("newName")
+
+		s.mu.Lock()
+		entity, ok := st.entities[oldName]
+		if ok {
+			st.setName(&entity, newName)
+			delete(st.entities, oldName)
+			st.entities[newName] = entity
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", oldName))
+
+			return
+		}
+
+		writeJSON(w, map[string]string{"status": "success"})
+	})
+
+	mux.HandleFunc("DELETE "+prefix+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		s.mu.Lock()
+		_, ok := st.entities[name]
+		delete(st.entities, name)
+		s.mu.Unlock()
+
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%s not found", name))
+
+			return
+		}
+
+		writeJSON(w, map[string]string{"status": "success"})
+	})
+}
+
+func echoChatScript(chatRequest *gofabric.ChatRequest) []gofabric.StreamResponse {
+	var userInput []string
+	for _, prompt := range chatRequest.Prompts {
+		userInput = append(userInput, prompt.UserInput)
+	}
+
+	return []gofabric.StreamResponse{
+		{
+			Type:    string(gofabric.StreamResponseTypeContent),
+			Format:  "markdown",
+			Content: strings.Join(userInput, "\n"),
+		},
+		{
+			Type:   string(gofabric.StreamResponseTypeComplete),
+			Format: "plain",
+		},
+	}
+}
+
+// wait delays the response to r by d. It returns false if the request is cancelled while waiting.
+func wait(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,312 @@
+package gofabrictest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func TestServerEntities(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	ctx := context.Background()
+
+	if err := client.CreatePattern(ctx, "summarize", strings.NewReader("Summarize {{input}}")); err != nil {
+		t.Fatalf("Failed to create pattern: %v", err)
+	}
+
+	exists, err := client.PatternExists(ctx, "summarize")
+	if err != nil || !exists {
+		t.Fatalf("Expected pattern to exist: %v, %v", exists, err)
+	}
+
+	if err := client.RenamePattern(ctx, "summarize", "summarize_v2"); err != nil {
+		t.Fatalf("Failed to rename pattern: %v", err)
+	}
+
+	pattern, err := client.GetPatternMetadata(ctx, "summarize_v2")
+	if err != nil {
+		t.Fatalf("Failed to get pattern: %v", err)
+	}
+
+	want := &gofabric.Pattern{Name: "summarize_v2", Pattern: "Summarize {{input}}"}
+	if diff := cmp.Diff(want, pattern); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	server.SetPattern(gofabric.Pattern{Name: "analyze"})
+
+	names, err := client.ListPatterns(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"analyze", "summarize_v2"}, names); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := client.DeletePattern(ctx, "summarize_v2"); err != nil {
+		t.Fatalf("Failed to delete pattern: %v", err)
+	}
+
+	if _, err := client.GetPatternMetadata(ctx, "summarize_v2"); !errors.Is(err, gofabric.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestServerSessions(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	ctx := context.Background()
+
+	body := `[{"role":"user","content":"Hi"},{"role":"assistant","content":"Hello"}]`
+	if err := client.CreateSession(ctx, "chat", strings.NewReader(body)); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	session, err := client.GetSessionMetadata(ctx, "chat")
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+
+	stored, _ := server.Session("chat")
+	if diff := cmp.Diff(&stored, session); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if len(session.Messages) != 2 || session.Messages[1].Content != "Hello" {
+		t.Fatalf("Unexpected messages: %+v", session.Messages)
+	}
+
+	if err := client.CreateSession(ctx, "broken", strings.NewReader("not json")); err == nil {
+		t.Fatal("Expected an error creating a session with an invalid body")
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	ctx := context.Background()
+
+	want := gofabric.Config{OpenAI: "openai_key"}
+	if err := client.UpdateConfig(ctx, &want); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+
+	config, err := client.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	if diff := cmp.Diff(&want, config); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerChat(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatResponses(
+		gofabric.StreamResponse{Type: string(gofabric.StreamResponseTypeContent), Format: "markdown", Content: "Hello"},
+		gofabric.StreamResponse{Type: string(gofabric.StreamResponseTypeContent), Format: "markdown", Content: " world"},
+		gofabric.StreamResponse{Type: string(gofabric.StreamResponseTypeComplete), Format: "plain"},
+	)
+
+	chatRequest := &gofabric.ChatRequest{
+		Prompts: []gofabric.PromptRequest{{UserInput: "Hi", PatternName: "greet"}},
+	}
+
+	result, err := server.Client().ChatComplete(context.Background(), chatRequest)
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	if diff := cmp.Diff("Hello world", result.Content); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]gofabric.ChatRequest{*chatRequest}, server.ChatRequests()); diff != "" {
+		t.Fatalf("Requests mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer(gofabrictest.WithAPIKey("secret"))
+	defer server.Close()
+
+	server.InjectFault(gofabrictest.Fault{
+		Method:     http.MethodGet,
+		Path:       "/patterns/names",
+		StatusCode: http.StatusServiceUnavailable,
+		Count:      1,
+	})
+
+	ctx := context.Background()
+
+	if _, err := gofabric.NewClient(server.URL).ListPatterns(ctx); !errors.Is(err, gofabric.ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got: %v", err)
+	}
+
+	client := server.Client()
+
+	if _, err := client.ListPatterns(ctx); !errors.Is(err, gofabric.ErrServerUnavailable) {
+		t.Fatalf("Expected ErrServerUnavailable, got: %v", err)
+	}
+
+	if _, err := client.ListPatterns(ctx); err != nil {
+		t.Fatalf("Expected the fault to be exhausted, got: %v", err)
+	}
+}
+
+func TestServerLatency(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := server.Client().ListContexts(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func setChatWords(server *gofabrictest.Server, words ...string) {
+	var responses []gofabric.StreamResponse
+	for _, word := range words {
+		responses = append(responses, gofabric.StreamResponse{
+			Type:    string(gofabric.StreamResponseTypeContent),
+			Format:  "markdown",
+			Content: word,
+		})
+	}
+
+	server.SetChatResponses(append(responses, gofabric.StreamResponse{
+		Type:   string(gofabric.StreamResponseTypeComplete),
+		Format: "plain",
+	})...)
+}
+
+func TestServerChatEventIDs(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	setChatWords(server, "Hello", " world")
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/chat", strings.NewReader(`{"prompts":[]}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+
+	want := "id: 2\ndata: {\"type\":\"content\",\"format\":\"markdown\",\"content\":\" world\"}\n\n" +
+		"id: 3\ndata: {\"type\":\"complete\",\"format\":\"plain\",\"content\":\"\"}\n\n"
+	if diff := cmp.Diff(want, string(body)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerChatDisconnect(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	setChatWords(server, "Hello", ",", " world")
+	server.InjectDisconnect(gofabrictest.Disconnect{After: 1, Count: 3})
+
+	chatRequest := &gofabric.ChatRequest{
+		Prompts: []gofabric.PromptRequest{{UserInput: "Hi", SessionName: "greetings"}},
+	}
+
+	// Without resumption, the dropped stream fails.
+	if _, err := server.Client().ChatComplete(context.Background(), chatRequest); err == nil {
+		t.Fatal("Expected the dropped stream to fail")
+	}
+
+	// The resumed stream is dropped once more, then resumed again up to its completion.
+	client := server.Client(gofabric.WithStreamResume(2))
+
+	result, err := client.ChatComplete(context.Background(), chatRequest)
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	if diff := cmp.Diff("Hello, world", result.Content); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := len(server.ChatRequests()); got != 4 {
+		t.Fatalf("Expected 4 chat requests, got %d", got)
+	}
+
+	// Only the completed stream is recorded in the session, with its full content.
+	session, ok := server.Session("greetings")
+	if !ok {
+		t.Fatal("Expected the session to be created")
+	}
+
+	want := []gofabric.Message{gofabric.UserMessage("Hi"), gofabric.AssistantMessage("Hello, world")}
+	if diff := cmp.Diff(want, session.Messages); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerChatIgnoreLastEventID(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	setChatWords(server, "Hello", " world")
+	server.InjectDisconnect(gofabrictest.Disconnect{After: 1, Count: 1})
+	server.SetIgnoreLastEventID(true)
+
+	chatRequest := &gofabric.ChatRequest{Prompts: []gofabric.PromptRequest{{UserInput: "Hi"}}}
+
+	_, err := server.Client(gofabric.WithStreamResume(1)).ChatComplete(context.Background(), chatRequest)
+	if !errors.Is(err, gofabric.ErrStreamNotResumed) {
+		t.Fatalf("Expected ErrStreamNotResumed, got: %v", err)
+	}
+}
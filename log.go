@@ -0,0 +1,103 @@
+package gofabric
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LevelTrace is the level of the most verbose logs of the Client, such as the SSE events of chat
+// streams. It is below slog.LevelDebug.
+const LevelTrace = slog.LevelDebug - 4
+
+// redacted replaces secrets in logs.
+const redacted = "REDACTED"
+
+// WithLogger sets the logger of the client. Requests and responses are logged at the debug level,
+// and the SSE events of chat streams at LevelTrace. The API key header and the API keys of Config
+// are redacted. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// sensitiveHeaderWords are the words of the names of the headers redacted from logs, which cover
+// X-API-Key, Authorization and the headers an API key may be moved to by APIKeyHeader.
+var sensitiveHeaderWords = []string{"auth", "cookie", "key", "secret", "token"}
+
+// headersLogValue returns the headers as a log group, redacting the sensitive ones.
+func headersLogValue(header http.Header) slog.Value {
+	attrs := make([]slog.Attr, 0, len(header))
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		value := strings.Join(header[name], ", ")
+
+		lowerName := strings.ToLower(name)
+		for _, word := range sensitiveHeaderWords {
+			if strings.Contains(lowerName, word) {
+				value = redacted
+			}
+		}
+
+		attrs = append(attrs, slog.String(name, value))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// logRequest logs an attempt at sending a request at the debug level.
+func (c *Client) logRequest(
+	ctx context.Context,
+	op operation,
+	attempt int,
+	req *http.Request,
+	resp *http.Response,
+	err error,
+	latency time.Duration,
+) {
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", op.name),
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+		slog.Any("headers", headersLogValue(req.Header)),
+	}
+
+	if op.entityName != "" {
+		attrs = append(attrs, slog.String("entity", string(op.entityType)+"/"+op.entityName))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "fabric request failed", attrs...)
+
+		return
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "fabric request", attrs...)
+}
+
+// logEvent logs an SSE event of a chat stream at LevelTrace.
+func (c *Client) logEvent(ctx context.Context, id string, streamResponse StreamResponse) {
+	c.logger.LogAttrs(
+		ctx,
+		LevelTrace,
+		"fabric stream event",
+		slog.String("id", id),
+		slog.String("type", streamResponse.Type),
+		slog.String("format", streamResponse.Format),
+		slog.String("content", streamResponse.Content),
+	)
+}
@@ -0,0 +1,178 @@
+package gofabric_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+// logBuffer is a concurrency-safe buffer of JSON log records.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// records returns the decoded log records with the specified message.
+func (b *logBuffer) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for line := range strings.Lines(b.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+func newTestLogger(buf *logBuffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: gofabric.LevelTrace}))
+}
+
+func TestWithLoggerRequests(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer(gofabrictest.WithAPIKey("server-secret"))
+	defer server.Close()
+
+	var buf logBuffer
+	client := server.Client(gofabric.WithAPIKey("server-secret"), gofabric.WithLogger(newTestLogger(&buf)))
+
+	if err := client.UpdateConfig(context.Background(), &gofabric.Config{OpenAI: "sk-secret"}); err != nil {
+		t.Fatalf("Failed to update config: %v", err)
+	}
+
+	if _, err := client.GetPatternMetadata(context.Background(), "missing"); err == nil {
+		t.Fatal("Expected an error for a missing pattern")
+	}
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("Expected the secrets to be redacted, got:\n%s", buf.String())
+	}
+
+	records := buf.records(t, "fabric request")
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 request records, got %d", len(records))
+	}
+
+	for _, record := range records {
+		delete(record, "time")
+		delete(record, "latency")
+	}
+
+	want := []map[string]any{
+		{
+			"level":     "DEBUG",
+			"msg":       "fabric request",
+			"operation": "UpdateConfig",
+			"method":    "PUT",
+			"path":      "/config/update",
+			"attempt":   float64(1),
+			"status":    float64(200),
+			"headers": map[string]any{
+				"Content-Type": "application/json",
+				"X-Api-Key":    "REDACTED",
+			},
+		},
+		{
+			"level":     "DEBUG",
+			"msg":       "fabric request",
+			"operation": "GetPatternMetadata",
+			"method":    "GET",
+			"path":      "/patterns/missing",
+			"attempt":   float64(1),
+			"status":    float64(404),
+			"entity":    "pattern/missing",
+			"headers": map[string]any{
+				"X-Api-Key": "REDACTED",
+			},
+		},
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	config := buf.records(t, "fabric config update")
+	if len(config) != 1 || config[0]["config"].(map[string]any)["openai"] != "REDACTED" {
+		t.Fatalf("Expected the config update to be logged with the key redacted, got %v", config)
+	}
+}
+
+func TestWithLoggerStreamEvents(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatResponses(
+		gofabric.StreamResponse{Type: "content", Format: "markdown", Content: "Hello"},
+		gofabric.StreamResponse{Type: "complete", Format: "plain"},
+	)
+
+	var buf logBuffer
+	client := server.Client(gofabric.WithLogger(newTestLogger(&buf)))
+
+	if _, err := client.ChatComplete(context.Background(), testChatRequest()); err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	var got []string
+	for _, record := range buf.records(t, "fabric stream event") {
+		if record["level"] != "DEBUG-4" {
+			t.Errorf("Expected the trace level, got %v", record["level"])
+		}
+
+		got = append(got, record["type"].(string)+":"+record["content"].(string))
+	}
+
+	if diff := cmp.Diff([]string{"content:Hello", "complete:"}, got); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConfigLogValue(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("config", "config", gofabric.Config{Anthropic: "sk-ant", Ollama: ""})
+
+	got := buf.String()
+	if strings.Contains(got, "sk-ant") {
+		t.Fatalf("Expected the key to be redacted, got %s", got)
+	}
+
+	for _, want := range []string{"config.anthropic=REDACTED", `config.ollama=""`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %s in %s", want, got)
+		}
+	}
+}
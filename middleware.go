@@ -0,0 +1,92 @@
+package gofabric
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Request is a request sent by the Client, as seen by middleware.
+type Request struct {
+	// Operation is the name of the Client method sending the request, e.g. "CreatePattern" or
+	// "Chat".
+	Operation string
+	// EntityType is the type of the entity the operation acts on, empty for operations that do not
+	// act on an entity.
+	EntityType EntityType
+	// EntityName is the name of the entity the operation acts on, empty for operations that do not
+	// act on a single entity.
+	EntityName string
+	// ChatRequest is the chat request of chat operations, nil for other operations. It must not be
+	// modified.
+	ChatRequest *ChatRequest
+	// Attempt is the number of the attempt, starting at 1, when the request is retried.
+	Attempt int
+	// HTTP is the HTTP request, authenticated by the Authenticator of the Client.
+	HTTP *http.Request
+}
+
+// Doer sends requests.
+type Doer interface {
+	// Do sends the request and returns the HTTP response. As with http.Client.Do, a non-2xx response
+	// is not an error.
+	Do(req *Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as a Doer.
+type DoerFunc func(req *Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to add behavior to every request sent by the Client, such as setting
+// headers, logging or recording metrics. It may modify req.HTTP, or replace it with a clone, before
+// calling next.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware adds middleware to the chain every request goes through before being sent by the
+// HTTP client. The first middleware is the outermost one. Middleware runs once per attempt when the
+// request is retried, and once per reconnection when a chat stream is resumed.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// buildDoer returns the Doer sending requests through the middleware chain.
+func (c *Client) buildDoer() Doer {
+	var doer Doer = DoerFunc(func(req *Request) (*http.Response, error) {
+		return c.httpClient.Do(req.HTTP)
+	})
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		doer = c.middleware[i](doer)
+	}
+
+	return doer
+}
+
+// operation describes the Client operation a request is sent for.
+type operation struct {
+	name        string
+	entityType  EntityType
+	entityName  string
+	chatRequest *ChatRequest
+}
+
+// entityOperation returns the operation acting on an entity. format holds a %s verb replaced with
+// the capitalized entity type, e.g. "Create%s".
+func entityOperation(format string, entityType EntityType, entityName string) operation {
+	title := string(entityType)
+	if title != "" {
+		title = strings.ToUpper(title[:1]) + title[1:]
+	}
+
+	return operation{
+		name:       fmt.Sprintf(format, title),
+		entityType: entityType,
+		entityName: entityName,
+	}
+}
@@ -0,0 +1,208 @@
+package gofabric_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+// requestRecorder is a middleware recording the requests going through it.
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []gofabric.Request
+}
+
+func (r *requestRecorder) middleware(next gofabric.Doer) gofabric.Doer {
+	return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+		r.mu.Lock()
+		r.requests = append(r.requests, *req)
+		r.mu.Unlock()
+
+		return next.Do(req)
+	})
+}
+
+// operations returns the operation, entity type, entity name and attempt of the recorded requests.
+func (r *requestRecorder) operations() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var operations []string
+	for _, req := range r.requests {
+		operations = append(
+			operations,
+			strings.Join([]string{req.Operation, string(req.EntityType), req.EntityName}, " "),
+		)
+	}
+
+	return operations
+}
+
+func TestMiddlewareOperations(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var recorder requestRecorder
+	client := server.Client(gofabric.WithMiddleware(recorder.middleware))
+	ctx := context.Background()
+
+	if err := client.CreatePattern(ctx, "summarize", strings.NewReader("Summarize")); err != nil {
+		t.Fatalf("Failed to create pattern: %v", err)
+	}
+
+	if _, err := client.ListContexts(ctx); err != nil {
+		t.Fatalf("Failed to list contexts: %v", err)
+	}
+
+	if _, err := client.SessionExists(ctx, "review"); err != nil {
+		t.Fatalf("Failed to check session: %v", err)
+	}
+
+	if err := client.RenamePattern(ctx, "summarize", "summarize_v2"); err != nil {
+		t.Fatalf("Failed to rename pattern: %v", err)
+	}
+
+	if _, err := client.GetConfig(ctx); err != nil {
+		t.Fatalf("Failed to get config: %v", err)
+	}
+
+	if _, err := client.ChatComplete(ctx, testChatRequest()); err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	want := []string{
+		"CreatePattern pattern summarize",
+		"ListContexts context ",
+		"SessionExists session review",
+		"RenamePattern pattern summarize",
+		"GetConfig  ",
+		"ChatComplete  ",
+	}
+	if diff := cmp.Diff(want, recorder.operations()); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if recorder.requests[5].ChatRequest == nil {
+		t.Fatal("Expected the chat request to be set")
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var order []string
+	named := func(name string) gofabric.Middleware {
+		return func(next gofabric.Doer) gofabric.Doer {
+			return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+				order = append(order, name+" before")
+				resp, err := next.Do(req)
+				order = append(order, name+" after")
+
+				return resp, err
+			})
+		}
+	}
+
+	client := server.Client(
+		gofabric.WithMiddleware(named("outer")),
+		gofabric.WithMiddleware(named("inner")),
+	)
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	want := []string{"outer before", "inner before", "inner after", "outer after"}
+	if diff := cmp.Diff(want, order); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMiddlewareRewritesRequest(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer(gofabrictest.WithAPIKey("secret"))
+	defer server.Close()
+
+	// The middleware authenticates the requests in place of WithAPIKey.
+	client := gofabric.NewClient(server.URL, gofabric.WithMiddleware(func(next gofabric.Doer) gofabric.Doer {
+		return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+			req.HTTP = req.HTTP.Clone(req.HTTP.Context())
+			req.HTTP.Header.Set("X-API-Key", "secret")
+
+			return next.Do(req)
+		})
+	}))
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+}
+
+func TestMiddlewareShortCircuits(t *testing.T) {
+	t.Parallel()
+
+	client := gofabric.NewClient("http://fabric.invalid", gofabric.WithMiddleware(func(gofabric.Doer) gofabric.Doer {
+		return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`["cached"]`)),
+				Request:    req.HTTP,
+			}, nil
+		})
+	}))
+
+	names, err := client.ListPatterns(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"cached"}, names); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMiddlewareRunsPerAttempt(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.InjectFault(gofabrictest.Fault{Path: "/patterns/names", StatusCode: http.StatusServiceUnavailable, Count: 2})
+
+	var recorder requestRecorder
+	client := server.Client(
+		gofabric.WithMiddleware(recorder.middleware),
+		gofabric.WithRetryPolicy(&gofabric.ExponentialBackoff{
+			MaxAttempts:     3,
+			InitialInterval: time.Millisecond,
+			MaxInterval:     time.Millisecond,
+		}),
+	)
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	var attempts []int
+	for _, req := range recorder.requests {
+		attempts = append(attempts, req.Attempt)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, attempts); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
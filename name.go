@@ -0,0 +1,75 @@
+package gofabric
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxNameLength is the maximum length in bytes of a file name on the file systems Fabric stores its
+// entities on.
+const maxNameLength = 255
+
+// ValidateName reports whether name can be the name of an entity of the specified type, following
+// the rules of the file system Fabric stores its entities on: patterns are directories, contexts are
+// files and sessions are files with a ".json" extension. It returns an *InvalidNameError if the name
+// is empty, "." or "..", contains a path separator or a control character, is not valid UTF-8, or is
+// too long.
+//
+// The Client validates entity names before sending any request.
+func ValidateName(entityType EntityType, name string) error {
+	reason := invalidNameReason(entityType, name)
+	if reason == "" {
+		return nil
+	}
+
+	return &InvalidNameError{EntityType: entityType, Name: name, Reason: reason}
+}
+
+func invalidNameReason(entityType EntityType, name string) string {
+	if name == "" {
+		return "name is empty"
+	}
+
+	if name == "." || name == ".." {
+		return "name is a relative path"
+	}
+
+	if !utf8.ValidString(name) {
+		return "name is not valid UTF-8"
+	}
+
+	if strings.ContainsAny(name, `/\`) {
+		return "name contains a path separator"
+	}
+
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return "name contains a control character"
+	}
+
+	maxLength := maxNameLength
+	if entityType == EntityTypeSession {
+		maxLength -= len(".json")
+	}
+
+	if len(name) > maxLength {
+		return fmt.Sprintf("name is longer than %d bytes", maxLength)
+	}
+
+	return ""
+}
+
+// entityPath returns the path of the endpoint of an entity type made of the specified segments, each
+// one percent-encoded.
+func entityPath(entityType EntityType, segments ...string) string {
+	var b strings.Builder
+
+	b.WriteString("/" + string(entityType) + "s")
+	for _, segment := range segments {
+		b.WriteString("/" + url.PathEscape(segment))
+	}
+
+	return b.String()
+}
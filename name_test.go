@@ -0,0 +1,147 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func TestValidateName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		entityType gofabric.EntityType
+		name       string
+		wantReason string
+	}{
+		{entityType: gofabric.EntityTypePattern, name: "summarize"},
+		{entityType: gofabric.EntityTypePattern, name: "what? #1 50%"},
+		{entityType: gofabric.EntityTypeContext, name: ".hidden"},
+		{entityType: gofabric.EntityTypePattern, name: "", wantReason: "name is empty"},
+		{entityType: gofabric.EntityTypePattern, name: ".", wantReason: "name is a relative path"},
+		{entityType: gofabric.EntityTypeContext, name: "..", wantReason: "name is a relative path"},
+		{entityType: gofabric.EntityTypePattern, name: "a/b", wantReason: "name contains a path separator"},
+		{entityType: gofabric.EntityTypeSession, name: `a\b`, wantReason: "name contains a path separator"},
+		{entityType: gofabric.EntityTypeContext, name: "a\x00b", wantReason: "name contains a control character"},
+		{entityType: gofabric.EntityTypeContext, name: "a\nb", wantReason: "name contains a control character"},
+		{entityType: gofabric.EntityTypePattern, name: "\xff", wantReason: "name is not valid UTF-8"},
+		{entityType: gofabric.EntityTypeContext, name: strings.Repeat("a", 255)},
+		{
+			entityType: gofabric.EntityTypeContext,
+			name:       strings.Repeat("a", 256),
+			wantReason: "name is longer than 255 bytes",
+		},
+		{
+			entityType: gofabric.EntityTypeSession,
+			name:       strings.Repeat("a", 251),
+			wantReason: "name is longer than 250 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		err := gofabric.ValidateName(tt.entityType, tt.name)
+
+		if tt.wantReason == "" {
+			if err != nil {
+				t.Errorf("Expected %s name %q to be valid, got %v", tt.entityType, tt.name, err)
+			}
+
+			continue
+		}
+
+		var nameErr *gofabric.InvalidNameError
+		if !errors.As(err, &nameErr) || !errors.Is(err, gofabric.ErrInvalidName) {
+			t.Errorf("Expected an *InvalidNameError for %s name %q, got %v", tt.entityType, tt.name, err)
+
+			continue
+		}
+
+		want := gofabric.InvalidNameError{EntityType: tt.entityType, Name: tt.name, Reason: tt.wantReason}
+		if diff := cmp.Diff(want, *nameErr); diff != "" {
+			t.Errorf("Mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestInvalidNameNotSent(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var recorder requestRecorder
+	client := server.Client(gofabric.WithMiddleware(recorder.middleware))
+	ctx := context.Background()
+
+	server.SetPattern(gofabric.Pattern{Name: "summarize", Pattern: "Summarize"})
+
+	errs := []error{
+		client.CreatePattern(ctx, "../contexts/secret", strings.NewReader("Summarize")),
+		client.DeleteContext(ctx, "a/b"),
+		client.RenamePattern(ctx, "summarize", "../summarize"),
+	}
+
+	_, err := client.GetSessionMetadata(ctx, "..")
+	errs = append(errs, err)
+
+	_, err = client.SessionExists(ctx, "")
+	errs = append(errs, err)
+
+	for _, err := range errs {
+		var entityErr *gofabric.EntityError
+		if !errors.As(err, &entityErr) || !errors.Is(err, gofabric.ErrInvalidName) {
+			t.Errorf("Expected an *EntityError wrapping an *InvalidNameError, got %v", err)
+		}
+	}
+
+	if got := recorder.operations(); len(got) != 0 {
+		t.Fatalf("Expected no request to be sent, got %v", got)
+	}
+}
+
+func TestEntityNamesEscaped(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	client := server.Client()
+	ctx := context.Background()
+
+	const name = "what? #1 50%"
+
+	if err := client.CreateContext(ctx, name, strings.NewReader("content")); err != nil {
+		t.Fatalf("Failed to create context: %v", err)
+	}
+
+	if _, ok := server.Context(name); !ok {
+		t.Fatalf("Expected context %q to be created", name)
+	}
+
+	if err := client.RenameContext(ctx, name, name+" (renamed)"); err != nil {
+		t.Fatalf("Failed to rename context: %v", err)
+	}
+
+	context, err := client.GetContextMetadata(ctx, name+" (renamed)")
+	if err != nil {
+		t.Fatalf("Failed to get context: %v", err)
+	}
+
+	if context.Content != "content" {
+		t.Fatalf("Expected the renamed context content, got %q", context.Content)
+	}
+
+	exists, err := client.ContextExists(ctx, name)
+	if err != nil {
+		t.Fatalf("Failed to check context: %v", err)
+	}
+
+	if exists {
+		t.Fatalf("Expected context %q not to exist after the rename", name)
+	}
+}
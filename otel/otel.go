@@ -0,0 +1,251 @@
+// Package otel instruments gofabric clients with OpenTelemetry.
+//
+// The middleware returned by Middleware emits a client span for every request sent by a
+// gofabric.Client, named after the operation (e.g. "CreatePattern" or "Chat"), records metrics of
+// the chat streams and propagates the trace context to the Fabric server:
+//
+//	client := gofabric.NewClient(
+//		"http://localhost:8080",
+//		gofabric.WithMiddleware(otel.Middleware()),
+//	)
+//
+// The span of a chat request ends when its stream ends, so it covers the whole stream. When a
+// request is retried, or a chat stream resumed, every attempt has its own span.
+package otel
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sherif-fanous/gofabric"
+	global "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer and meter.
+const ScopeName = "github.com/sherif-fanous/gofabric/otel"
+
+// Attribute keys of the spans and metrics.
+const (
+	OperationKey  = attribute.Key("fabric.operation")
+	EntityTypeKey = attribute.Key("fabric.entity.type")
+	EntityNameKey = attribute.Key("fabric.entity.name")
+	VendorKey     = attribute.Key("fabric.vendor")
+	ModelKey      = attribute.Key("fabric.model")
+	PatternKey    = attribute.Key("fabric.pattern")
+	StrategyKey   = attribute.Key("fabric.strategy")
+	AttemptKey    = attribute.Key("fabric.attempt")
+	ChunksKey     = attribute.Key("fabric.chat.chunks")
+	BytesKey      = attribute.Key("fabric.chat.bytes")
+)
+
+// Option configures the middleware.
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the tracer provider creating the spans. The global tracer provider is used
+// by default.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tracerProvider
+	}
+}
+
+// WithMeterProvider sets the meter provider recording the metrics. The global meter provider is used
+// by default.
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = meterProvider
+	}
+}
+
+// WithPropagator sets the propagator injecting the trace context in the request headers. W3C trace
+// context propagation is used by default.
+func WithPropagator(propagator propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagator = propagator
+	}
+}
+
+// instruments holds the metric instruments of the chat streams.
+type instruments struct {
+	timeToFirstToken metric.Float64Histogram
+	chunks           metric.Int64Histogram
+	bytes            metric.Int64Histogram
+	duration         metric.Float64Histogram
+}
+
+func newInstruments(meter metric.Meter) instruments {
+	var inst instruments
+	var err error
+
+	// Instruments that fail to be created are replaced with no-op ones by the meter, so errors are
+	// only reported.
+	inst.timeToFirstToken, err = meter.Float64Histogram(
+		"fabric.chat.time_to_first_token",
+		metric.WithDescription("Time elapsed until the first content of a chat stream was received."),
+		metric.WithUnit("s"),
+	)
+	handleError(err)
+
+	inst.chunks, err = meter.Int64Histogram(
+		"fabric.chat.chunks",
+		metric.WithDescription("Number of content chunks received in a chat stream."),
+		metric.WithUnit("{chunk}"),
+	)
+	handleError(err)
+
+	inst.bytes, err = meter.Int64Histogram(
+		"fabric.chat.bytes",
+		metric.WithDescription("Size of the content received in a chat stream."),
+		metric.WithUnit("By"),
+	)
+	handleError(err)
+
+	inst.duration, err = meter.Float64Histogram(
+		"fabric.chat.duration",
+		metric.WithDescription("Time elapsed until a chat stream ended."),
+		metric.WithUnit("s"),
+	)
+	handleError(err)
+
+	return inst
+}
+
+func handleError(err error) {
+	if err != nil {
+		global.Handle(err)
+	}
+}
+
+// Middleware returns a middleware tracing the requests sent by a gofabric.Client and recording the
+// metrics of its chat streams.
+func Middleware(opts ...Option) gofabric.Middleware {
+	cfg := config{
+		tracerProvider: global.GetTracerProvider(),
+		meterProvider:  global.GetMeterProvider(),
+		propagator:     propagation.TraceContext{},
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tracer := cfg.tracerProvider.Tracer(ScopeName, trace.WithSchemaURL(semconv.SchemaURL))
+	inst := newInstruments(cfg.meterProvider.Meter(ScopeName, metric.WithSchemaURL(semconv.SchemaURL)))
+
+	return func(next gofabric.Doer) gofabric.Doer {
+		return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+			start := time.Now()
+			attrs := requestAttributes(req)
+
+			ctx, span := tracer.Start(
+				req.HTTP.Context(),
+				req.Operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithTimestamp(start),
+				trace.WithAttributes(attrs...),
+				trace.WithAttributes(
+					AttemptKey.Int(req.Attempt),
+					semconv.HTTPRequestMethodKey.String(req.HTTP.Method),
+					semconv.ServerAddress(req.HTTP.URL.Hostname()),
+				),
+			)
+
+			req.HTTP = req.HTTP.WithContext(ctx)
+			req.HTTP.Header = req.HTTP.Header.Clone()
+			cfg.propagator.Inject(ctx, propagation.HeaderCarrier(req.HTTP.Header))
+
+			resp, err := next.Do(req)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+
+				return resp, err
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+				span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+				span.End()
+
+				return resp, nil
+			}
+
+			if req.ChatRequest == nil {
+				span.End()
+
+				return resp, nil
+			}
+
+			// The span of a chat request covers the stream, and ends when the body is closed.
+			resp.Body = &streamBody{
+				ReadCloser: resp.Body,
+				ctx:        ctx,
+				span:       span,
+				inst:       inst,
+				attrs:      metric.WithAttributeSet(attribute.NewSet(metricAttributes(req)...)),
+				start:      start,
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// requestAttributes returns the span attributes describing the operation of req.
+func requestAttributes(req *gofabric.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(req.Operation)}
+
+	if req.EntityType != "" {
+		attrs = append(attrs, EntityTypeKey.String(string(req.EntityType)))
+	}
+
+	if req.EntityName != "" {
+		attrs = append(attrs, EntityNameKey.String(req.EntityName))
+	}
+
+	if req.ChatRequest != nil && len(req.ChatRequest.Prompts) > 0 {
+		prompt := req.ChatRequest.Prompts[0]
+
+		for _, attr := range []attribute.KeyValue{
+			VendorKey.String(prompt.Vendor),
+			ModelKey.String(prompt.Model),
+			PatternKey.String(prompt.PatternName),
+			StrategyKey.String(prompt.StrategyName),
+		} {
+			if attr.Value.AsString() != "" {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+
+	return attrs
+}
+
+// metricAttributes returns the attributes of the chat stream metrics, leaving out the entity and
+// pattern names to keep their cardinality low.
+func metricAttributes(req *gofabric.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{OperationKey.String(req.Operation)}
+
+	if len(req.ChatRequest.Prompts) > 0 {
+		prompt := req.ChatRequest.Prompts[0]
+		attrs = append(attrs, VendorKey.String(prompt.Vendor), ModelKey.String(prompt.Model))
+	}
+
+	return attrs
+}
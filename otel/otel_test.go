@@ -0,0 +1,224 @@
+package otel_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+	"github.com/sherif-fanous/gofabric/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// telemetry records the spans and metrics emitted by the middleware.
+type telemetry struct {
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		spans:  tracetest.NewSpanRecorder(),
+		reader: sdkmetric.NewManualReader(),
+	}
+}
+
+func (tel *telemetry) middleware() gofabric.Middleware {
+	return otel.Middleware(
+		otel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(tel.spans))),
+		otel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(tel.reader))),
+	)
+}
+
+// histograms returns the count and sum of the histograms recorded, by name.
+func (tel *telemetry) histograms(t *testing.T) map[string][2]float64 {
+	t.Helper()
+
+	var metrics metricdata.ResourceMetrics
+	if err := tel.reader.Collect(context.Background(), &metrics); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	histograms := make(map[string][2]float64)
+	for _, scopeMetrics := range metrics.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[int64]:
+				for _, point := range data.DataPoints {
+					histograms[m.Name] = [2]float64{float64(point.Count), float64(point.Sum)}
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range data.DataPoints {
+					histograms[m.Name] = [2]float64{float64(point.Count), point.Sum}
+				}
+			}
+		}
+	}
+
+	return histograms
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+
+	return attrs
+}
+
+func TestMiddlewareEntitySpans(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	tel := newTelemetry()
+	client := server.Client(gofabric.WithMiddleware(tel.middleware()))
+	ctx := context.Background()
+
+	if err := client.CreatePattern(ctx, "summarize", strings.NewReader("Summarize")); err != nil {
+		t.Fatalf("Failed to create pattern: %v", err)
+	}
+
+	if _, err := client.GetContextMetadata(ctx, "missing"); err == nil {
+		t.Fatal("Expected an error for a missing context")
+	}
+
+	spans := tel.spans.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "CreatePattern" || spans[0].SpanKind() != trace.SpanKindClient {
+		t.Errorf("Unexpected span %q of kind %v", spans[0].Name(), spans[0].SpanKind())
+	}
+
+	want := map[attribute.Key]string{
+		"fabric.operation":          "CreatePattern",
+		"fabric.entity.type":        "pattern",
+		"fabric.entity.name":        "summarize",
+		"fabric.attempt":            "1",
+		"http.request.method":       "POST",
+		"http.response.status_code": "200",
+		"server.address":            "127.0.0.1",
+	}
+	if diff := cmp.Diff(want, spanAttributes(spans[0])); diff != "" {
+		t.Errorf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected an error status, got %v", spans[1].Status())
+	}
+
+	if got := spanAttributes(spans[1])["http.response.status_code"]; got != "404" {
+		t.Errorf("Expected status code 404, got %s", got)
+	}
+}
+
+func TestMiddlewareChatStream(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatResponses(
+		gofabric.StreamResponse{Type: "content", Format: "markdown", Content: "Hello, "},
+		gofabric.StreamResponse{Type: "content", Format: "markdown", Content: "world!"},
+		gofabric.StreamResponse{Type: "complete", Format: "plain"},
+	)
+
+	tel := newTelemetry()
+	client := server.Client(gofabric.WithMiddleware(tel.middleware()))
+
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithUserInput("Hello"),
+		gofabric.WithModel("OpenAI", "gpt-4o"),
+		gofabric.WithPattern("summarize"),
+		gofabric.WithStrategy("cot"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build chat request: %v", err)
+	}
+
+	if _, err := client.ChatComplete(context.Background(), chatRequest); err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	spans := tel.spans.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	attrs := spanAttributes(spans[0])
+	for key, want := range map[attribute.Key]string{
+		"fabric.operation":   "ChatComplete",
+		"fabric.vendor":      "OpenAI",
+		"fabric.model":       "gpt-4o",
+		"fabric.pattern":     "summarize",
+		"fabric.strategy":    "cot",
+		"fabric.chat.chunks": "2",
+		"fabric.chat.bytes":  "13",
+	} {
+		if attrs[key] != want {
+			t.Errorf("Expected %s to be %q, got %q", key, want, attrs[key])
+		}
+	}
+
+	histograms := tel.histograms(t)
+
+	if got := histograms["fabric.chat.chunks"]; got != [2]float64{1, 2} {
+		t.Errorf("Expected 1 stream of 2 chunks, got %v", got)
+	}
+
+	if got := histograms["fabric.chat.bytes"]; got != [2]float64{1, 13} {
+		t.Errorf("Expected 1 stream of 13 bytes, got %v", got)
+	}
+
+	for _, name := range []string{"fabric.chat.time_to_first_token", "fabric.chat.duration"} {
+		if got := histograms[name]; got[0] != 1 {
+			t.Errorf("Expected 1 %s measurement, got %v", name, got[0])
+		}
+	}
+}
+
+func TestMiddlewarePropagatesTraceContext(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	tel := newTelemetry()
+
+	var traceparent string
+	client := server.Client(gofabric.WithMiddleware(
+		tel.middleware(),
+		func(next gofabric.Doer) gofabric.Doer {
+			return gofabric.DoerFunc(func(req *gofabric.Request) (*http.Response, error) {
+				traceparent = req.HTTP.Header.Get("traceparent")
+
+				return next.Do(req)
+			})
+		},
+	))
+
+	if _, err := client.ListPatterns(context.Background()); err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	spanContext := tel.spans.Ended()[0].SpanContext()
+	want := "00-" + spanContext.TraceID().String() + "-" + spanContext.SpanID().String() + "-01"
+
+	if traceparent != want {
+		t.Errorf("Expected traceparent %q, got %q", want, traceparent)
+	}
+}
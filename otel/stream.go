@@ -0,0 +1,136 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/sherif-fanous/gofabric"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// streamBody wraps the body of a chat response to observe the SSE events read by the client. It
+// records the metrics of the stream and ends its span when the body is read to the end or closed.
+type streamBody struct {
+	io.ReadCloser
+
+	ctx   context.Context
+	span  trace.Span
+	inst  instruments
+	attrs metric.MeasurementOption
+	start time.Time
+
+	// The incomplete line read last
+	line []byte
+	// The data of the event being read
+	data [][]byte
+
+	chunks           int64
+	bytes            int64
+	timeToFirstToken time.Duration
+
+	once sync.Once
+}
+
+func (b *streamBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.scan(p[:n])
+
+	switch {
+	case errors.Is(err, io.EOF):
+		b.end(nil)
+	case err != nil:
+		b.end(err)
+	}
+
+	return n, err
+}
+
+func (b *streamBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.end(nil)
+
+	return err
+}
+
+// scan splits p into lines and dispatches the events they complete.
+func (b *streamBody) scan(p []byte) {
+	b.line = append(b.line, p...)
+
+	for {
+		i := bytes.IndexByte(b.line, '\n')
+		if i < 0 {
+			return
+		}
+
+		line := bytes.TrimSuffix(b.line[:i], []byte("\r"))
+		b.line = b.line[i+1:]
+
+		if len(line) == 0 {
+			b.dispatch()
+
+			continue
+		}
+
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			b.data = append(b.data, bytes.TrimPrefix(data, []byte(" ")))
+		}
+	}
+}
+
+// dispatch records the event whose data has been read.
+func (b *streamBody) dispatch() {
+	if len(b.data) == 0 {
+		return
+	}
+
+	data := bytes.Join(b.data, []byte("\n"))
+	b.data = b.data[:0]
+
+	var streamResponse gofabric.StreamResponse
+	if err := json.Unmarshal(data, &streamResponse); err != nil {
+		return
+	}
+
+	switch streamResponse.Type {
+	case string(gofabric.StreamResponseTypeContent):
+		if b.chunks == 0 {
+			b.timeToFirstToken = time.Since(b.start)
+		}
+
+		b.chunks++
+		b.bytes += int64(len(streamResponse.Content))
+	case string(gofabric.StreamResponseTypeError):
+		b.span.SetStatus(codes.Error, streamResponse.Content)
+	}
+}
+
+// end records the metrics of the stream and ends its span, once.
+func (b *streamBody) end(err error) {
+	b.once.Do(func() {
+		duration := time.Since(b.start)
+
+		if b.chunks > 0 {
+			b.inst.timeToFirstToken.Record(b.ctx, b.timeToFirstToken.Seconds(), b.attrs)
+		}
+
+		b.inst.chunks.Record(b.ctx, b.chunks, b.attrs)
+		b.inst.bytes.Record(b.ctx, b.bytes, b.attrs)
+		b.inst.duration.Record(b.ctx, duration.Seconds(), b.attrs)
+
+		b.span.SetAttributes(ChunksKey.Int64(b.chunks), BytesKey.Int64(b.bytes))
+
+		if err != nil {
+			b.span.RecordError(err)
+			b.span.SetStatus(codes.Error, err.Error())
+		}
+
+		b.span.End()
+	})
+}
@@ -0,0 +1,194 @@
+package gofabric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is the limit of a token bucket: Rate tokens are added per second, up to Burst tokens,
+// and every chat request takes one token.
+type RateLimit struct {
+	Rate  float64 // Rate is the number of chat requests allowed per second, zero or less for no limit.
+	Burst int     // Burst is the number of chat requests allowed at once, 1 if zero or less.
+}
+
+// RateLimits configures a RateLimiter. The zero value does not limit anything.
+type RateLimits struct {
+	Global  RateLimit            // Global limits all the chat requests.
+	Vendors map[string]RateLimit // Vendors limits the chat requests by vendor name, case-insensitively.
+	Models  map[string]RateLimit // Models limits the chat requests by model name, case-insensitively.
+
+	// MaxInFlightStreams is the maximum number of chat streams open at once, zero or less for no
+	// limit. A stream is in flight from the time the chat request is sent until the stream ends,
+	// including while it is resumed.
+	MaxInFlightStreams int
+}
+
+// RateLimiter throttles the chat requests of one or more clients, so that they wait for their turn
+// instead of failing with rate limit errors from the LLM vendors. A chat request reserves a token of
+// the global bucket and of the buckets of the vendors and models of its prompts, waits until all of
+// them are available, then waits for a stream slot.
+//
+// A RateLimiter is safe for concurrent use, and can be shared by several clients.
+type RateLimiter struct {
+	global  *tokenBucket
+	vendors map[string]*tokenBucket
+	models  map[string]*tokenBucket
+	streams chan struct{}
+}
+
+// NewRateLimiter returns a RateLimiter enforcing the specified limits.
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	limiter := &RateLimiter{
+		global:  newTokenBucket(limits.Global),
+		vendors: make(map[string]*tokenBucket),
+		models:  make(map[string]*tokenBucket),
+	}
+
+	for vendor, limit := range limits.Vendors {
+		if bucket := newTokenBucket(limit); bucket != nil {
+			limiter.vendors[strings.ToLower(vendor)] = bucket
+		}
+	}
+
+	for model, limit := range limits.Models {
+		if bucket := newTokenBucket(limit); bucket != nil {
+			limiter.models[strings.ToLower(model)] = bucket
+		}
+	}
+
+	if limits.MaxInFlightStreams > 0 {
+		limiter.streams = make(chan struct{}, limits.MaxInFlightStreams)
+	}
+
+	return limiter
+}
+
+// WithRateLimiter throttles the chat requests of the client with the rate limiter. Chat, ChatStream
+// and ChatComplete block until the request is allowed or the context is done.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// Wait blocks until the chat request is allowed to be sent or ctx is done, and returns the function
+// releasing its stream slot, which must be called when the stream ends.
+func (l *RateLimiter) Wait(ctx context.Context, chatRequest *ChatRequest) (func(), error) {
+	buckets := []*tokenBucket{l.global}
+
+	for _, prompt := range chatRequest.Prompts {
+		buckets = append(
+			buckets,
+			l.vendors[strings.ToLower(prompt.Vendor)],
+			l.models[strings.ToLower(prompt.Model)],
+		)
+	}
+
+	// A token is reserved from every bucket up front, and the request waits for the longest delay,
+	// so that it does not hold the tokens of some buckets while waiting for another one in turn.
+	var reserved []*tokenBucket
+	var delay time.Duration
+
+	for _, bucket := range buckets {
+		// A bucket shared by several prompts is only reserved once.
+		if bucket == nil || slices.Contains(reserved, bucket) {
+			continue
+		}
+
+		delay = max(delay, bucket.reserve())
+		reserved = append(reserved, bucket)
+	}
+
+	// The tokens are given back when the request is not allowed after all, so that cancelled
+	// requests do not use up capacity.
+	refund := func() {
+		for _, bucket := range reserved {
+			bucket.cancel()
+		}
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			refund()
+
+			return nil, fmt.Errorf("failed to wait for rate limiter: %w", ctx.Err())
+		}
+	}
+
+	if l.streams == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.streams <- struct{}{}:
+	case <-ctx.Done():
+		refund()
+
+		return nil, fmt.Errorf("failed to wait for rate limiter: %w", ctx.Err())
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { <-l.streams })
+	}, nil
+}
+
+// tokenBucket is a token bucket letting callers reserve tokens ahead of time.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full token bucket with the specified limit, or nil if there is no limit.
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	burst := float64(max(limit.Burst, 1))
+
+	return &tokenBucket{rate: limit.Rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token, possibly going into debt, and returns the delay until the token is
+// actually available.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.burst)
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(-b.tokens / b.rate * float64(time.Second)))
+}
+
+// cancel gives back a token reserved by a caller that stopped waiting, or taken by a request that
+// was not allowed after all.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+1, b.burst)
+}
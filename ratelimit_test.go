@@ -0,0 +1,229 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func chatRequestFor(t *testing.T, vendor string, model string) *gofabric.ChatRequest {
+	t.Helper()
+
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithUserInput("Hello"),
+		gofabric.WithModel(vendor, model),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build chat request: %v", err)
+	}
+
+	return chatRequest
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{
+		Global: gofabric.RateLimit{Rate: 20, Burst: 1},
+	})
+	client := server.Client(gofabric.WithRateLimiter(limiter))
+
+	start := time.Now()
+
+	for range 3 {
+		if _, err := client.ChatComplete(context.Background(), testChatRequest()); err != nil {
+			t.Fatalf("Failed to chat: %v", err)
+		}
+	}
+
+	// The first request takes the burst token, the next two wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("Expected the requests to be throttled, took %s", elapsed)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	var recorder requestRecorder
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{
+		Vendors: map[string]gofabric.RateLimit{"openai": {Rate: 0.001}},
+	})
+	client := server.Client(
+		gofabric.WithRateLimiter(limiter),
+		gofabric.WithMiddleware(recorder.middleware),
+	)
+
+	_, err := client.ChatComplete(context.Background(), chatRequestFor(t, "OpenAI", "gpt-4o"))
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = client.ChatComplete(ctx, chatRequestFor(t, "OpenAI", "gpt-4o-mini"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the wait to end with the context, got %v", err)
+	}
+
+	// Other vendors are not limited.
+	_, err = client.ChatComplete(context.Background(), chatRequestFor(t, "Anthropic", "claude"))
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	if got := len(recorder.operations()); got != 2 {
+		t.Fatalf("Expected 2 requests to be sent, got %d", got)
+	}
+}
+
+func TestRateLimiterModels(t *testing.T) {
+	t.Parallel()
+
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{
+		Models: map[string]gofabric.RateLimit{"GPT-4o": {Rate: 0.001, Burst: 2}},
+	})
+
+	for range 2 {
+		release, err := limiter.Wait(context.Background(), chatRequestFor(t, "OpenAI", "gpt-4o"))
+		if err != nil {
+			t.Fatalf("Failed to wait: %v", err)
+		}
+
+		release()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := limiter.Wait(ctx, chatRequestFor(t, "OpenAI", "gpt-4o"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the burst to be exhausted, got %v", err)
+	}
+}
+
+func TestRateLimiterRefund(t *testing.T) {
+	t.Parallel()
+
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{
+		Global:  gofabric.RateLimit{Rate: 0.001, Burst: 2},
+		Vendors: map[string]gofabric.RateLimit{"openai": {Rate: 0.001}},
+	})
+
+	// Exhaust the vendor bucket, leaving a single global token.
+	release, err := limiter.Wait(context.Background(), chatRequestFor(t, "OpenAI", "gpt-4o"))
+	if err != nil {
+		t.Fatalf("Failed to wait: %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The global token is reserved along with the vendor token, then the wait for the latter is
+	// cancelled.
+	_, err = limiter.Wait(ctx, chatRequestFor(t, "OpenAI", "gpt-4o"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the vendor bucket to be exhausted, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Wait(ctx, chatRequestFor(t, "Anthropic", "claude")); err != nil {
+		t.Fatalf("Expected the global token to be given back, got %v", err)
+	}
+}
+
+func TestRateLimiterThrottledVendor(t *testing.T) {
+	t.Parallel()
+
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{
+		Global:  gofabric.RateLimit{Rate: 100, Burst: 2},
+		Vendors: map[string]gofabric.RateLimit{"openai": {Rate: 0.001}},
+	})
+
+	// Exhaust the vendor bucket.
+	release, err := limiter.Wait(context.Background(), chatRequestFor(t, "OpenAI", "gpt-4o"))
+	if err != nil {
+		t.Fatalf("Failed to wait: %v", err)
+	}
+	release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chatRequest := chatRequestFor(t, "OpenAI", "gpt-4o")
+	throttled := make(chan error, 1)
+	go func() {
+		_, err := limiter.Wait(ctx, chatRequest)
+		throttled <- err
+	}()
+
+	// The throttled request waits for the vendor token without holding back the other vendors.
+	for range 3 {
+		start := time.Now()
+
+		release, err := limiter.Wait(context.Background(), chatRequestFor(t, "Anthropic", "claude"))
+		if err != nil {
+			t.Fatalf("Failed to wait: %v", err)
+		}
+		release()
+
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Fatalf("Expected the other vendor not to be held back, took %s", elapsed)
+		}
+	}
+
+	cancel()
+
+	if err := <-throttled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the throttled request to wait for the vendor token, got %v", err)
+	}
+}
+
+func TestRateLimiterMaxInFlightStreams(t *testing.T) {
+	t.Parallel()
+
+	server := gofabrictest.NewServer()
+	defer server.Close()
+
+	server.SetChatResponses(
+		gofabric.StreamResponse{Type: "content", Format: "markdown", Content: "Hello"},
+		gofabric.StreamResponse{Type: "complete", Format: "plain"},
+	)
+
+	limiter := gofabric.NewRateLimiter(gofabric.RateLimits{MaxInFlightStreams: 1})
+	client := server.Client(gofabric.WithRateLimiter(limiter))
+
+	streamResponses, err := client.Chat(context.Background(), testChatRequest())
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	// The stream holds the only slot until it is drained.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Chat(ctx, testChatRequest()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the second stream to wait for a slot, got %v", err)
+	}
+
+	for range streamResponses {
+	}
+
+	if _, err := client.ChatComplete(context.Background(), testChatRequest()); err != nil {
+		t.Fatalf("Failed to chat once the first stream ended: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package gofabric
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	minTemperature = 0.0
+	maxTemperature = 2.0
+	minTopP        = 0.0
+	maxTopP        = 1.0
+	minPenalty     = -2.0
+	maxPenalty     = 2.0
+)
+
+// ChatRequestOption represents a function that configures a ChatRequest built by NewChatRequest.
+//
+// Options that configure a prompt apply to the single prompt of the request.
+type ChatRequestOption func(*ChatRequest)
+
+// NewChatRequest builds a ChatRequest holding a single prompt from the specified options, and
+// validates it.
+//
+// For example:
+//
+//	chatRequest, err := gofabric.NewChatRequest(
+//		gofabric.WithUserInput("A Hacker News clone"),
+//		gofabric.WithModel("Gemini", "gemini-2.0-flash"),
+//		gofabric.WithPattern("create_prd"),
+//		gofabric.WithTemperature(0.2),
+//	)
+func NewChatRequest(opts ...ChatRequestOption) (*ChatRequest, error) {
+	chatRequest := &ChatRequest{
+		Prompts: []PromptRequest{{}},
+	}
+
+	for _, opt := range opts {
+		opt(chatRequest)
+	}
+
+	if err := chatRequest.Validate(); err != nil {
+		return nil, err
+	}
+
+	return chatRequest, nil
+}
+
+// WithUserInput sets the user input of the prompt.
+func WithUserInput(userInput string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].UserInput = userInput
+	}
+}
+
+// WithModel sets the vendor and model of the prompt.
+func WithModel(vendor string, model string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].Vendor = vendor
+		r.Prompts[0].Model = model
+	}
+}
+
+// WithPattern sets the pattern of the prompt.
+func WithPattern(patternName string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].PatternName = patternName
+	}
+}
+
+// WithVariable sets the value of a pattern variable of the prompt.
+func WithVariable(name string, value string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		if r.Prompts[0].Variables == nil {
+			r.Prompts[0].Variables = make(map[string]string)
+		}
+		r.Prompts[0].Variables[name] = value
+	}
+}
+
+// WithVariables sets the values of pattern variables of the prompt.
+func WithVariables(vars map[string]string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		for name, value := range vars {
+			WithVariable(name, value)(r)
+		}
+	}
+}
+
+// WithStrategy sets the strategy of the prompt.
+func WithStrategy(strategyName string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].StrategyName = strategyName
+	}
+}
+
+// WithContextName sets the context of the prompt.
+func WithContextName(contextName string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].ContextName = contextName
+	}
+}
+
+// WithSessionName sets the session the exchange is recorded in.
+func WithSessionName(sessionName string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Prompts[0].SessionName = sessionName
+	}
+}
+
+// WithLanguage sets the language of the chat.
+func WithLanguage(language string) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.Language = language
+	}
+}
+
+// WithTemperature sets the temperature, between 0 and 2.
+func WithTemperature(temperature float64) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.Temperature = &temperature
+	}
+}
+
+// WithTopP sets the nucleus sampling parameter, between 0 and 1.
+func WithTopP(topP float64) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.TopP = &topP
+	}
+}
+
+// WithPresencePenalty sets the presence penalty, between -2 and 2.
+func WithPresencePenalty(presencePenalty float64) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.PresencePenalty = &presencePenalty
+	}
+}
+
+// WithFrequencyPenalty sets the frequency penalty, between -2 and 2.
+func WithFrequencyPenalty(frequencyPenalty float64) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.FrequencyPenalty = &frequencyPenalty
+	}
+}
+
+// WithSeed sets the seed used for random number generation.
+func WithSeed(seed int) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.Seed = &seed
+	}
+}
+
+// WithModelContextLength sets the maximum context length of the model.
+func WithModelContextLength(modelContextLength int) ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.ModelContextLength = &modelContextLength
+	}
+}
+
+// WithRaw requests the raw model output without processing.
+func WithRaw() ChatRequestOption {
+	return func(r *ChatRequest) {
+		r.ChatOptions.Raw = true
+	}
+}
+
+// Validate checks the chat request for errors that would make the server reject it or silently
+// ignore part of it. It returns every *ValidationError found, joined with errors.Join.
+//
+// The Client validates chat requests before sending them.
+func (r *ChatRequest) Validate() error {
+	var errs []error
+
+	addError := func(field string, format string, args ...any) {
+		errs = append(errs, &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(r.Prompts) == 0 {
+		addError("prompts", "at least one prompt is required")
+	}
+
+	for i, prompt := range r.Prompts {
+		field := fmt.Sprintf("prompts[%d]", i)
+
+		switch {
+		case prompt.Vendor == "" && prompt.Model != "":
+			addError(field+".vendor", "vendor is required when model %q is set", prompt.Model)
+		case prompt.Vendor != "" && prompt.Model == "":
+			addError(field+".model", "model is required when vendor %q is set", prompt.Vendor)
+		}
+
+		if prompt.PatternName == "" && prompt.UserInput == "" {
+			addError(field, "either a pattern or user input is required")
+		}
+
+		// A strategy shapes the system prompt, which the raw mode bypasses.
+		if prompt.StrategyName != "" && r.ChatOptions.Raw {
+			addError(
+				field+".strategyName",
+				"strategy %q cannot be used with raw output",
+				prompt.StrategyName,
+			)
+		}
+	}
+
+	validateRange := func(field string, value *float64, minValue float64, maxValue float64) {
+		if value != nil && (*value < minValue || *value > maxValue) {
+			addError(field, "%g is out of range [%g, %g]", *value, minValue, maxValue)
+		}
+	}
+
+	validateRange("chatOptions.temperature", r.ChatOptions.Temperature, minTemperature, maxTemperature)
+	validateRange("chatOptions.topP", r.ChatOptions.TopP, minTopP, maxTopP)
+	validateRange("chatOptions.presencePenalty", r.ChatOptions.PresencePenalty, minPenalty, maxPenalty)
+	validateRange("chatOptions.frequencyPenalty", r.ChatOptions.FrequencyPenalty, minPenalty, maxPenalty)
+
+	if length := r.ChatOptions.ModelContextLength; length != nil && *length <= 0 {
+		addError("chatOptions.modelContextLength", "%d must be positive", *length)
+	}
+
+	return errors.Join(errs...)
+}
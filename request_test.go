@@ -0,0 +1,151 @@
+package gofabric_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+func TestNewChatRequest(t *testing.T) {
+	t.Parallel()
+
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithUserInput("A Hacker News clone"),
+		gofabric.WithModel("Gemini", "gemini-2.0-flash"),
+		gofabric.WithPattern("create_prd"),
+		gofabric.WithLanguage("en"),
+		gofabric.WithTemperature(0),
+		gofabric.WithSeed(42),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build chat request: %v", err)
+	}
+
+	data, err := json.Marshal(chatRequest)
+	if err != nil {
+		t.Fatalf("Failed to encode chat request: %v", err)
+	}
+
+	want := `{"prompts":[{"userInput":"A Hacker News clone","vendor":"Gemini","model":"gemini-2.0-flash",` +
+		`"contextName":"","patternName":"create_prd","strategyName":"","sessionName":""}],` +
+		`"language":"en","chatOptions":{"temperature":0,"seed":42}}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatRequestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		opts       []gofabric.ChatRequestOption
+		wantFields []string
+	}{
+		{
+			name: "valid",
+			opts: []gofabric.ChatRequestOption{
+				gofabric.WithUserInput("input"),
+				gofabric.WithStrategy("cot"),
+				gofabric.WithTopP(1),
+				gofabric.WithPresencePenalty(-2),
+			},
+		},
+		{
+			name:       "empty prompt",
+			wantFields: []string{"prompts[0]"},
+		},
+		{
+			name: "strategy without pattern or input",
+			opts: []gofabric.ChatRequestOption{
+				gofabric.WithStrategy("cot"),
+			},
+			wantFields: []string{"prompts[0]"},
+		},
+		{
+			name: "strategy with raw output",
+			opts: []gofabric.ChatRequestOption{
+				gofabric.WithPattern("summarize"),
+				gofabric.WithStrategy("cot"),
+				gofabric.WithRaw(),
+			},
+			wantFields: []string{"prompts[0].strategyName"},
+		},
+		{
+			name: "model without vendor",
+			opts: []gofabric.ChatRequestOption{
+				gofabric.WithPattern("summarize"),
+				gofabric.WithModel("", "gpt-4o"),
+			},
+			wantFields: []string{"prompts[0].vendor"},
+		},
+		{
+			name: "out of range options",
+			opts: []gofabric.ChatRequestOption{
+				gofabric.WithPattern("summarize"),
+				gofabric.WithTemperature(2.5),
+				gofabric.WithTopP(-0.1),
+				gofabric.WithFrequencyPenalty(3),
+				gofabric.WithModelContextLength(0),
+			},
+			wantFields: []string{
+				"chatOptions.temperature",
+				"chatOptions.topP",
+				"chatOptions.frequencyPenalty",
+				"chatOptions.modelContextLength",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := gofabric.NewChatRequest(tt.opts...)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, gofabric.ErrInvalidRequest) {
+				t.Fatalf("Expected ErrInvalidRequest, got: %v", err)
+			}
+
+			var fields []string
+			for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+				var validationErr *gofabric.ValidationError
+				if errors.As(err, &validationErr) {
+					fields = append(fields, validationErr.Field)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantFields, fields); diff != "" {
+				t.Fatalf("Fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestChatValidatesRequest(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+	_, err := client.Chat(context.Background(), &gofabric.ChatRequest{})
+	if !errors.Is(err, gofabric.ErrInvalidRequest) {
+		t.Fatalf("Expected ErrInvalidRequest, got: %v", err)
+	}
+}
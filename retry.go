@@ -0,0 +1,146 @@
+package gofabric
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts     = 4
+	defaultRetryInitialInterval = 250 * time.Millisecond
+	defaultRetryMaxInterval     = 10 * time.Second
+)
+
+// RetryPolicy decides whether a failed request should be attempted again.
+//
+// Retry is called after every failed attempt with the 1-based number of the attempt that failed.
+// Exactly one of resp and err is non-nil: resp is set when the server answered with a status code
+// other than 200 (its body has already been consumed), err is set when the request could not be
+// executed at all. Retry returns the delay to wait before the next attempt and whether a next
+// attempt should be made.
+//
+// The Client only consults the policy for failures it can replay: non-200 responses, which are
+// received before any SSE stream starts, and transport errors for idempotent methods (GET, PUT and
+// DELETE). In particular a POST to /chat is never replayed once the SSE stream has started. A
+// gateway answering 502 or 504 may still have forwarded the request, so a policy retrying those
+// statuses accepts that a POST may be processed twice.
+type RetryPolicy interface {
+	Retry(attempt int, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff is a RetryPolicy that retries connection errors, 429 and 5xx responses using
+// exponential backoff with full jitter. A Retry-After header sent by the server takes precedence
+// over the computed delay and is honored in full, even beyond MaxInterval; the Client gives up
+// instead if the context would expire before the delay elapses.
+//
+// The zero value is ready to use and makes up to 4 attempts, starting with a 250ms interval that is
+// capped at 10s.
+type ExponentialBackoff struct {
+	MaxAttempts     int           // MaxAttempts is the total number of attempts, including the first one.
+	InitialInterval time.Duration // InitialInterval is the upper bound of the delay before the first retry.
+	MaxInterval     time.Duration // MaxInterval caps the computed delay between two attempts.
+}
+
+// Retry implements the RetryPolicy interface.
+func (b ExponentialBackoff) Retry(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	maxAttempts := b.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultRetryMaxAttempts
+	}
+
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+
+	if resp != nil && !isRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+
+	// Retrying earlier than the server allows would only be rejected again.
+	if delay, ok := retryAfter(resp); ok {
+		return delay, true
+	}
+
+	maxInterval := b.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultRetryMaxInterval
+	}
+
+	initialInterval := b.InitialInterval
+	if initialInterval <= 0 {
+		initialInterval = defaultRetryInitialInterval
+	}
+
+	// Stop doubling once the interval reaches maxInterval, so that the shift cannot overflow.
+	interval := maxInterval
+	if shift := attempt - 1; shift < 63 && initialInterval <= maxInterval>>shift {
+		interval = initialInterval << shift
+	}
+
+	return rand.N(interval + 1), true
+}
+
+// WithRetryPolicy sets the retry policy for the client.
+// By default the client makes a single attempt per request.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// retryAfter parses the Retry-After header of resp, which is either a number of seconds or an
+// HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// sleep waits for delay to elapse. It returns false without waiting if the context would expire
+// before the delay elapses, or if it is cancelled while waiting.
+func sleep(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,244 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := gofabric.ExponentialBackoff{
+		MaxAttempts:     3,
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+	}
+
+	tests := []struct {
+		name      string
+		attempt   int
+		resp      *http.Response
+		err       error
+		wantRetry bool
+		wantMin   time.Duration
+		wantMax   time.Duration
+	}{
+		{
+			name:      "connection error",
+			attempt:   1,
+			err:       errors.New("connection refused"),
+			wantRetry: true,
+			wantMax:   100 * time.Millisecond,
+		},
+		{
+			name:      "service unavailable",
+			attempt:   2,
+			resp:      &http.Response{StatusCode: http.StatusServiceUnavailable},
+			wantRetry: true,
+			wantMax:   200 * time.Millisecond,
+		},
+		{
+			name: "too many requests with retry after",
+			resp: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"30"}},
+			},
+			attempt:   1,
+			wantRetry: true,
+			wantMin:   30 * time.Second,
+			wantMax:   30 * time.Second,
+		},
+		{
+			name:      "not found",
+			attempt:   1,
+			resp:      &http.Response{StatusCode: http.StatusNotFound},
+			wantRetry: false,
+		},
+		{
+			name:      "attempts exhausted",
+			attempt:   3,
+			resp:      &http.Response{StatusCode: http.StatusBadGateway},
+			wantRetry: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			delay, retry := policy.Retry(tt.attempt, tt.resp, tt.err)
+			if retry != tt.wantRetry {
+				t.Fatalf("Retry() retry = %v, want %v", retry, tt.wantRetry)
+			}
+
+			if delay < tt.wantMin || delay > tt.wantMax {
+				t.Fatalf("Retry() delay = %v, want within [%v, %v]", delay, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffLongIntervals(t *testing.T) {
+	t.Parallel()
+
+	policy := gofabric.ExponentialBackoff{
+		MaxAttempts:     100,
+		InitialInterval: 10 * time.Second,
+		MaxInterval:     time.Minute,
+	}
+
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		delay, retry := policy.Retry(attempt, nil, errors.New("connection refused"))
+		if !retry {
+			t.Fatalf("Retry(%d) retry = false, want true", attempt)
+		}
+
+		if delay < 0 || delay > policy.MaxInterval {
+			t.Fatalf("Retry(%d) delay = %v, want within [0, %v]", attempt, delay, policy.MaxInterval)
+		}
+	}
+}
+
+func TestRetryPolicyRetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/patterns/test" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if diff := cmp.Diff("content", string(body)); diff != "" {
+			t.Errorf("Body mismatch (-want +got):\n%s", diff)
+		}
+
+		if attempts.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(
+		ts.URL,
+		gofabric.WithRetryPolicy(gofabric.ExponentialBackoff{InitialInterval: time.Millisecond}),
+	)
+	err := client.CreatePattern(context.Background(), "test", strings.NewReader("content"))
+	if err != nil {
+		t.Fatalf("Failed to create pattern: %v", err)
+	}
+
+	if diff := cmp.Diff(int32(3), attempts.Load()); diff != "" {
+		t.Fatalf("Attempts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(
+		ts.URL,
+		gofabric.WithRetryPolicy(gofabric.ExponentialBackoff{InitialInterval: time.Millisecond}),
+	)
+	_, err := client.GetPatternMetadata(context.Background(), "test")
+
+	var httpErr *gofabric.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Expected HTTP 404 error, got: %v", err)
+	}
+
+	if diff := cmp.Diff(int32(1), attempts.Load()); diff != "" {
+		t.Fatalf("Attempts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryPolicyRespectsContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	client := gofabric.NewClient(
+		ts.URL,
+		gofabric.WithRetryPolicy(gofabric.ExponentialBackoff{MaxInterval: time.Minute}),
+	)
+	start := time.Now()
+	_, err := client.ListPatterns(ctx)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Expected the client to give up before the deadline, waited %v", elapsed)
+	}
+
+	if diff := cmp.Diff(int32(1), attempts.Load()); diff != "" {
+		t.Fatalf("Attempts mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetryPolicyDoesNotReplayChatOnConnectionError(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+
+		// Drop the connection after the request has been received.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack connection: %v", err)
+
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(
+		ts.URL,
+		gofabric.WithRetryPolicy(gofabric.ExponentialBackoff{InitialInterval: time.Millisecond}),
+	)
+	_, err := client.Chat(context.Background(), testChatRequest())
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if diff := cmp.Diff(int32(1), attempts.Load()); diff != "" {
+		t.Fatalf("Attempts mismatch (-want +got):\n%s", diff)
+	}
+}
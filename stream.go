@@ -0,0 +1,174 @@
+package gofabric
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tmaxmax/go-sse"
+)
+
+const lastEventIDHeaderName = "Last-Event-ID"
+
+// WithStreamResume enables resumable chat streams.
+//
+// When a chat stream is interrupted before the server sends the "complete" message, the client
+// reconnects up to maxReconnects times, sending the ID of the last SSE event received in the
+// Last-Event-ID header so that the server can resume the stream. If the server does not assign
+// event IDs, or the stream cannot be resumed, the stream ends with a *StreamInterruptedError
+// carrying the content received so far.
+//
+// The first event of a resumed stream must carry an ID following the last one received, as a number
+// greater than it if both are numbers, or any other ID otherwise. A server ignoring the Last-Event-ID
+// header starts a new generation instead, which ends the stream with a *StreamInterruptedError
+// wrapping ErrStreamNotResumed rather than mixing the content of both generations.
+func WithStreamResume(maxReconnects int) Option {
+	return func(c *Client) {
+		c.streamResume = true
+		c.maxStreamReconnects = max(maxReconnects, 0)
+	}
+}
+
+// readStream reads the SSE stream of resp and calls yield for every response received until the
+// server sends the "complete" message, an error occurs, or yield returns false. Errors are passed to
+// yield with a zero StreamResponse and end the stream. A stream ending before the "complete" message
+// that is not resumed ends with a *StreamInterruptedError. The body of resp is always closed.
+//
+// data is the encoded chat request, which is sent again when resuming an interrupted stream.
+func (c *Client) readStream(
+	ctx context.Context,
+	op operation,
+	data []byte,
+	resp *http.Response,
+	yield func(StreamResponse, error) bool,
+) {
+	var content strings.Builder
+	var lastEventID, resumedFrom string
+
+	for reconnects := 0; ; reconnects++ {
+		done, readErr := c.readEvents(ctx, resp, resumedFrom, &content, &lastEventID, yield)
+		if done {
+			return
+		}
+
+		if err := ctx.Err(); err != nil {
+			yield(StreamResponse{}, err)
+
+			return
+		}
+
+		// A server that ignored the Last-Event-ID header would ignore it again.
+		err := readErr
+		resumable := c.streamResume && lastEventID != "" && !errors.Is(err, ErrStreamNotResumed)
+		if resumable && reconnects < c.maxStreamReconnects {
+			resumedFrom = lastEventID
+			resp, err = c.doRequest(
+				ctx,
+				op,
+				http.MethodPost,
+				"/chat",
+				bytes.NewReader(data),
+				withHeader(lastEventIDHeaderName, lastEventID),
+			)
+			if err == nil {
+				continue
+			}
+
+			err = fmt.Errorf("failed to resume chat: %w", err)
+		}
+
+		yield(StreamResponse{}, &StreamInterruptedError{
+			Content:     content.String(),
+			LastEventID: lastEventID,
+			Err:         err,
+		})
+
+		return
+	}
+}
+
+// readEvents reads SSE events from the body of resp until the stream ends, and closes it. It returns
+// done if the stream has been fully consumed or the caller stopped it, otherwise it returns the
+// error that interrupted the stream, which is nil if the stream ended prematurely without one.
+//
+// resumedFrom is the ID of the last event received before resuming the stream, empty for a new
+// stream. The stream is not read if its first event does not follow it.
+func (c *Client) readEvents(
+	ctx context.Context,
+	resp *http.Response,
+	resumedFrom string,
+	content *strings.Builder,
+	lastEventID *string,
+	yield func(StreamResponse, error) bool,
+) (bool, error) {
+	defer func() { _ = resp.Body.Close() }()
+
+	for event, err := range sse.Read(resp.Body, nil) {
+		if err != nil {
+			return false, fmt.Errorf("failed to read SSE response: %w", err)
+		}
+
+		if resumedFrom != "" {
+			if !eventIDFollows(event.LastEventID, resumedFrom) {
+				return false, fmt.Errorf(
+					"%w: first event ID %q does not follow %q",
+					ErrStreamNotResumed,
+					event.LastEventID,
+					resumedFrom,
+				)
+			}
+
+			resumedFrom = ""
+		}
+
+		*lastEventID = event.LastEventID
+
+		var streamResponse StreamResponse
+		if err := json.Unmarshal([]byte(event.Data), &streamResponse); err != nil {
+			yield(StreamResponse{}, fmt.Errorf("failed to parse SSE response: %w", decodeError(err)))
+
+			return true, nil
+		}
+
+		c.logEvent(ctx, event.LastEventID, streamResponse)
+
+		if streamResponse.Type == string(StreamResponseTypeContent) {
+			content.WriteString(streamResponse.Content)
+		}
+
+		// Stop iterating if the caller is no longer interested OR if the server has sent the
+		// "complete" message.
+		if !yield(streamResponse, nil) || streamResponse.Type == string(StreamResponseTypeComplete) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// eventIDFollows reports whether the SSE event ID id follows previous: it is greater if both are
+// numbers, and otherwise set and different.
+func eventIDFollows(id string, previous string) bool {
+	n, idErr := strconv.ParseUint(id, 10, 64)
+	p, previousErr := strconv.ParseUint(previous, 10, 64)
+	if idErr == nil && previousErr == nil {
+		return n > p
+	}
+
+	return id != "" && id != previous
+}
+
+// streamErrorResponse converts an error that ended a stream into an error StreamResponse.
+func streamErrorResponse(err error) StreamResponse {
+	return StreamResponse{
+		Type:    string(StreamResponseTypeError),
+		Format:  "plain",
+		Content: err.Error(),
+		Err:     err,
+	}
+}
@@ -0,0 +1,337 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+func testChatRequest() *gofabric.ChatRequest {
+	return &gofabric.ChatRequest{
+		Prompts: []gofabric.PromptRequest{{UserInput: "Hello"}},
+	}
+}
+
+func writeEvent(w http.ResponseWriter, id string, streamResponse gofabric.StreamResponse) {
+	if id != "" {
+		_, _ = fmt.Fprintf(w, "id: %s\n", id)
+	}
+	_, _ = fmt.Fprintf(
+		w,
+		"data: {\"type\":%q,\"format\":%q,\"content\":%q}\n\n",
+		streamResponse.Type,
+		streamResponse.Format,
+		streamResponse.Content,
+	)
+	w.(http.Flusher).Flush()
+}
+
+func contentResponse(content string) gofabric.StreamResponse {
+	return gofabric.StreamResponse{
+		Type:    string(gofabric.StreamResponseTypeContent),
+		Format:  "markdown",
+		Content: content,
+	}
+}
+
+func completeResponse() gofabric.StreamResponse {
+	return gofabric.StreamResponse{Type: string(gofabric.StreamResponseTypeComplete), Format: "plain"}
+}
+
+func TestChatStreamResume(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		switch attempts.Add(1) {
+		case 1:
+			writeEvent(w, "1", contentResponse("Hello"))
+			panic(http.ErrAbortHandler)
+		case 2:
+			if diff := cmp.Diff("1", r.Header.Get("Last-Event-ID")); diff != "" {
+				t.Errorf("Last-Event-ID mismatch (-want +got):\n%s", diff)
+			}
+			writeEvent(w, "2", contentResponse(", world"))
+			writeEvent(w, "3", completeResponse())
+		default:
+			t.Errorf("Unexpected attempt")
+		}
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL, gofabric.WithStreamResume(1))
+	responses, err := client.Chat(context.Background(), testChatRequest())
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	var got []gofabric.StreamResponse
+	for response := range responses {
+		got = append(got, response)
+	}
+
+	want := []gofabric.StreamResponse{
+		contentResponse("Hello"),
+		contentResponse(", world"),
+		completeResponse(),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatStreamResumeIgnored(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+
+	// The server ignores the Last-Event-ID header and starts a new generation on every request.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "1", contentResponse("Hello"))
+		panic(http.ErrAbortHandler)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL, gofabric.WithStreamResume(3))
+	responses, err := client.Chat(context.Background(), testChatRequest())
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	var got []gofabric.StreamResponse
+	for response := range responses {
+		got = append(got, response)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected the content of the first generation and an error, got %+v", got)
+	}
+
+	if diff := cmp.Diff(contentResponse("Hello"), got[0]); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(got[1].Err, gofabric.ErrStreamNotResumed) {
+		t.Fatalf("Expected ErrStreamNotResumed, got: %v", got[1].Err)
+	}
+
+	var interruptedErr *gofabric.StreamInterruptedError
+	if !errors.As(got[1].Err, &interruptedErr) {
+		t.Fatalf("Expected *StreamInterruptedError, got: %T", got[1].Err)
+	}
+
+	if diff := cmp.Diff("Hello", interruptedErr.Content); diff != "" {
+		t.Fatalf("Content mismatch (-want +got):\n%s", diff)
+	}
+
+	// The stream is not resumed again once the server has ignored the header.
+	if got := attempts.Load(); got != 2 {
+		t.Fatalf("Expected 2 requests, got %d", got)
+	}
+}
+
+func TestChatStreamInterrupted(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "", contentResponse("Hello"))
+		writeEvent(w, "", contentResponse(", wor"))
+		panic(http.ErrAbortHandler)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL, gofabric.WithStreamResume(3))
+	responses, err := client.Chat(context.Background(), testChatRequest())
+	if err != nil {
+		t.Fatalf("Failed to chat: %v", err)
+	}
+
+	var last gofabric.StreamResponse
+	for response := range responses {
+		last = response
+	}
+
+	if diff := cmp.Diff(string(gofabric.StreamResponseTypeError), last.Type); diff != "" {
+		t.Fatalf("Type mismatch (-want +got):\n%s", diff)
+	}
+
+	if !errors.Is(last.Err, gofabric.ErrStreamInterrupted) {
+		t.Fatalf("Expected ErrStreamInterrupted, got: %v", last.Err)
+	}
+
+	var interruptedErr *gofabric.StreamInterruptedError
+	if !errors.As(last.Err, &interruptedErr) {
+		t.Fatalf("Expected *StreamInterruptedError, got: %T", last.Err)
+	}
+
+	if diff := cmp.Diff("Hello, wor", interruptedErr.Content); diff != "" {
+		t.Fatalf("Content mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatStream(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat" {
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		writeEvent(w, "", contentResponse("Hello"))
+		writeEvent(w, "", contentResponse(", world"))
+		writeEvent(w, "", completeResponse())
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+
+	var got []gofabric.StreamResponse
+	for response, err := range client.ChatStream(context.Background(), testChatRequest()) {
+		if err != nil {
+			t.Fatalf("Failed to chat: %v", err)
+		}
+		got = append(got, response)
+	}
+
+	want := []gofabric.StreamResponse{
+		contentResponse("Hello"),
+		contentResponse(", world"),
+		completeResponse(),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		check   func(error) bool
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeEvent(w, "", gofabric.StreamResponse{
+					Type:    string(gofabric.StreamResponseTypeError),
+					Format:  "plain",
+					Content: "unknown model",
+				})
+			},
+			check: func(err error) bool {
+				var streamErr *gofabric.StreamError
+				return errors.As(err, &streamErr) && streamErr.Message == "unknown model"
+			},
+		},
+		{
+			name: "http error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			check: func(err error) bool {
+				return errors.Is(err, gofabric.ErrUnauthorized)
+			},
+		},
+		{
+			name: "premature end",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeEvent(w, "", contentResponse("Hello"))
+			},
+			check: func(err error) bool {
+				var interruptedErr *gofabric.StreamInterruptedError
+				return errors.As(err, &interruptedErr) && interruptedErr.Content == "Hello"
+			},
+		},
+		{
+			name: "disconnect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeEvent(w, "1", contentResponse("Hello"))
+				panic(http.ErrAbortHandler)
+			},
+			check: func(err error) bool {
+				return errors.Is(err, gofabric.ErrStreamInterrupted)
+			},
+		},
+		{
+			name: "malformed event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, "data: not json\n\n")
+			},
+			check: func(err error) bool {
+				return errors.Is(err, gofabric.ErrDecode)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			client := gofabric.NewClient(ts.URL)
+
+			var errs []error
+			for _, err := range client.ChatStream(context.Background(), testChatRequest()) {
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if len(errs) != 1 || !tt.check(errs[0]) {
+				t.Fatalf("Unexpected errors: %v", errs)
+			}
+		})
+	}
+}
+
+func TestChatStreamBreakClosesBody(t *testing.T) {
+	t.Parallel()
+
+	disconnected := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvent(w, "", contentResponse("Hello"))
+		<-r.Context().Done()
+		close(disconnected)
+	}))
+	defer ts.Close()
+
+	client := gofabric.NewClient(ts.URL)
+	for _, err := range client.ChatStream(context.Background(), testChatRequest()) {
+		if err != nil {
+			t.Fatalf("Failed to chat: %v", err)
+		}
+
+		break
+	}
+
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the connection to be closed after breaking out of the loop")
+	}
+}
@@ -0,0 +1,315 @@
+package gofabric
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultSyncConcurrency = 4
+	patternFileName        = "system.md"
+)
+
+// SyncAction represents the action taken on a pattern by a sync.
+type SyncAction string
+
+const (
+	SyncActionCreate    SyncAction = "create"
+	SyncActionUpdate    SyncAction = "update"
+	SyncActionDelete    SyncAction = "delete"
+	SyncActionUnchanged SyncAction = "unchanged"
+)
+
+// SyncOptions configures SyncPatterns and PullPatterns.
+type SyncOptions struct {
+	DryRun      bool // DryRun computes the plan without applying it.
+	Delete      bool // Delete removes the patterns missing from the source of the sync.
+	Concurrency int  // Concurrency is the maximum number of concurrent requests, 4 if not positive.
+}
+
+// SyncResult reports the action planned for, or taken on, a single pattern.
+type SyncResult struct {
+	Name       string     // Name is the name of the pattern.
+	Action     SyncAction // Action is the action taken on the pattern.
+	LocalHash  string     // LocalHash is the hex SHA-256 of the local content, empty if missing.
+	RemoteHash string     // RemoteHash is the hex SHA-256 of the server content, empty if missing.
+	Err        error      // Err is the error applying the action, nil on success or in a dry run.
+}
+
+// SyncReport holds the results of a sync, sorted by pattern name.
+type SyncReport struct {
+	DryRun  bool         // DryRun reports whether the plan was computed without being applied.
+	Results []SyncResult // Results holds the result for every pattern.
+}
+
+// Changed returns the results whose action is not SyncActionUnchanged.
+func (r *SyncReport) Changed() []SyncResult {
+	var changed []SyncResult
+	for _, result := range r.Results {
+		if result.Action != SyncActionUnchanged {
+			changed = append(changed, result)
+		}
+	}
+
+	return changed
+}
+
+// Err returns the errors of the failed actions joined with errors.Join, or nil if none failed.
+func (r *SyncReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SyncPatterns pushes a local patterns tree to the server. The tree holds a directory per pattern,
+// named after it and containing the pattern in a system.md file, like the patterns directory of
+// Fabric. Directories without a system.md file are ignored.
+//
+// The local patterns are compared with the server's by content hash: missing patterns are created,
+// differing ones are updated and, if opts.Delete is set, patterns missing from the tree are deleted.
+// Unless opts.DryRun is set, the plan is applied with at most opts.Concurrency concurrent requests.
+//
+// The returned error reports a failure to compute the plan; failures applying it are reported per
+// pattern in the SyncReport, see SyncReport.Err.
+func (c *Client) SyncPatterns(ctx context.Context, fsys fs.FS, opts SyncOptions) (*SyncReport, error) {
+	local, err := readLocalPatterns(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local patterns: %w", err)
+	}
+
+	remote, err := c.fetchPatterns(ctx, opts.concurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	report := planSync(local, remote, opts)
+	if opts.DryRun {
+		return report, nil
+	}
+
+	forEachResult(report, opts.concurrency(), func(result *SyncResult) {
+		switch result.Action {
+		case SyncActionCreate, SyncActionUpdate:
+			result.Err = c.CreatePattern(ctx, result.Name, strings.NewReader(local[result.Name]))
+		case SyncActionDelete:
+			result.Err = c.DeletePattern(ctx, result.Name)
+		}
+	})
+
+	return report, nil
+}
+
+// PullPatterns writes the server's patterns to the patterns tree rooted at dir, in the layout read by
+// SyncPatterns, creating dir if needed. It is the reverse of SyncPatterns: the actions of the results
+// apply to the tree, and opts.Delete removes the directories of the patterns missing from the server.
+func (c *Client) PullPatterns(ctx context.Context, dir string, opts SyncOptions) (*SyncReport, error) {
+	local, err := readLocalPatterns(os.DirFS(dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read local patterns: %w", err)
+	}
+
+	remote, err := c.fetchPatterns(ctx, opts.concurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	report := planSync(remote, local, opts)
+	for i := range report.Results {
+		result := &report.Results[i]
+		result.LocalHash, result.RemoteHash = result.RemoteHash, result.LocalHash
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	forEachResult(report, opts.concurrency(), func(result *SyncResult) {
+		if !filepath.IsLocal(result.Name) {
+			result.Err = fmt.Errorf("failed to pull pattern `%s`: invalid name", result.Name)
+
+			return
+		}
+
+		patternDir := filepath.Join(dir, result.Name)
+
+		switch result.Action {
+		case SyncActionCreate, SyncActionUpdate:
+			if err := os.MkdirAll(patternDir, 0o755); err != nil {
+				result.Err = err
+
+				return
+			}
+
+			result.Err = os.WriteFile(filepath.Join(patternDir, patternFileName), []byte(remote[result.Name]), 0o644)
+		case SyncActionDelete:
+			result.Err = os.RemoveAll(patternDir)
+		}
+	})
+
+	return report, nil
+}
+
+func (o SyncOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultSyncConcurrency
+	}
+
+	return o.Concurrency
+}
+
+// readLocalPatterns returns the content of the patterns of the tree, keyed by name.
+func readLocalPatterns(fsys fs.FS) (map[string]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(entry.Name(), patternFileName))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		patterns[entry.Name()] = string(content)
+	}
+
+	return patterns, nil
+}
+
+// fetchPatterns returns the content of the server's patterns, keyed by name.
+func (c *Client) fetchPatterns(ctx context.Context, concurrency int) (map[string]string, error) {
+	names, err := c.ListPatterns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		errs     []error
+		patterns = make(map[string]string, len(names))
+	)
+
+	forEach(names, concurrency, func(name string) {
+		pattern, err := c.GetPatternMetadata(ctx, name)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			errs = append(errs, err)
+
+			return
+		}
+
+		patterns[name] = pattern.Pattern
+	})
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// planSync computes the actions bringing target in line with source. The LocalHash of the results is
+// the hash of the source content and their RemoteHash the hash of the target content.
+func planSync(source map[string]string, target map[string]string, opts SyncOptions) *SyncReport {
+	report := &SyncReport{DryRun: opts.DryRun}
+
+	for name, content := range source {
+		result := SyncResult{Name: name, Action: SyncActionCreate, LocalHash: contentHash(content)}
+
+		if targetContent, ok := target[name]; ok {
+			result.RemoteHash = contentHash(targetContent)
+			result.Action = SyncActionUpdate
+			if result.RemoteHash == result.LocalHash {
+				result.Action = SyncActionUnchanged
+			}
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	if opts.Delete {
+		for name, content := range target {
+			if _, ok := source[name]; !ok {
+				report.Results = append(report.Results, SyncResult{
+					Name:       name,
+					Action:     SyncActionDelete,
+					RemoteHash: contentHash(content),
+				})
+			}
+		}
+	}
+
+	slices.SortFunc(report.Results, func(a SyncResult, b SyncResult) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return report
+}
+
+// forEachResult calls apply for every changed result of the report, with at most concurrency
+// concurrent calls.
+func forEachResult(report *SyncReport, concurrency int, apply func(result *SyncResult)) {
+	var changed []*SyncResult
+	for i := range report.Results {
+		if report.Results[i].Action != SyncActionUnchanged {
+			changed = append(changed, &report.Results[i])
+		}
+	}
+
+	forEach(changed, concurrency, apply)
+}
+
+// forEach calls fn for every item, with at most concurrency concurrent calls, and waits for them to
+// return.
+func forEach[T any](items []T, concurrency int, fn func(item T)) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for _, item := range items {
+		semaphore <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			fn(item)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,201 @@
+package gofabric_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/sherif-fanous/gofabric"
+	"github.com/sherif-fanous/gofabric/gofabrictest"
+)
+
+func newSyncTestServer(t *testing.T) *gofabrictest.Server {
+	t.Helper()
+
+	server := gofabrictest.NewServer()
+	t.Cleanup(server.Close)
+
+	server.SetPattern(gofabric.Pattern{Name: "summarize", Pattern: "Summarize {{input}}"})
+	server.SetPattern(gofabric.Pattern{Name: "translate", Pattern: "Translate {{input}}"})
+	server.SetPattern(gofabric.Pattern{Name: "obsolete", Pattern: "Obsolete"})
+
+	return server
+}
+
+var testPatternsTree = fstest.MapFS{
+	"summarize/system.md": {Data: []byte("Summarize {{input}}")},
+	"translate/system.md": {Data: []byte("Translate {{input}} to {{lang_code}}")},
+	"analyze/system.md":   {Data: []byte("Analyze {{input}}")},
+	"analyze/README.md":   {Data: []byte("Ignored")},
+	"drafts/notes.md":     {Data: []byte("Ignored")},
+	"README.md":           {Data: []byte("Ignored")},
+}
+
+func syncActions(report *gofabric.SyncReport) map[string]gofabric.SyncAction {
+	actions := make(map[string]gofabric.SyncAction)
+	for _, result := range report.Results {
+		actions[result.Name] = result.Action
+	}
+
+	return actions
+}
+
+func TestSyncPatternsDryRun(t *testing.T) {
+	t.Parallel()
+
+	server := newSyncTestServer(t)
+
+	report, err := server.Client().SyncPatterns(
+		context.Background(),
+		testPatternsTree,
+		gofabric.SyncOptions{DryRun: true, Delete: true},
+	)
+	if err != nil {
+		t.Fatalf("Failed to sync patterns: %v", err)
+	}
+
+	want := map[string]gofabric.SyncAction{
+		"analyze":   gofabric.SyncActionCreate,
+		"obsolete":  gofabric.SyncActionDelete,
+		"summarize": gofabric.SyncActionUnchanged,
+		"translate": gofabric.SyncActionUpdate,
+	}
+	if diff := cmp.Diff(want, syncActions(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	var names []string
+	for _, result := range report.Results {
+		names = append(names, result.Name)
+	}
+
+	if diff := cmp.Diff([]string{"analyze", "obsolete", "summarize", "translate"}, names); diff != "" {
+		t.Fatalf("Order mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, ok := server.Pattern("analyze"); ok {
+		t.Fatal("Expected the dry run not to create patterns")
+	}
+
+	if _, ok := server.Pattern("obsolete"); !ok {
+		t.Fatal("Expected the dry run not to delete patterns")
+	}
+}
+
+func TestSyncPatterns(t *testing.T) {
+	t.Parallel()
+
+	server := newSyncTestServer(t)
+
+	report, err := server.Client().SyncPatterns(context.Background(), testPatternsTree, gofabric.SyncOptions{})
+	if err != nil {
+		t.Fatalf("Failed to sync patterns: %v", err)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Fatalf("Failed to apply sync: %v", err)
+	}
+
+	if diff := cmp.Diff(2, len(report.Changed())); diff != "" {
+		t.Fatalf("Changed mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, content := range map[string]string{
+		"analyze":   "Analyze {{input}}",
+		"translate": "Translate {{input}} to {{lang_code}}",
+		"obsolete":  "Obsolete",
+	} {
+		pattern, ok := server.Pattern(name)
+		if !ok {
+			t.Fatalf("Expected pattern %q to exist", name)
+		}
+
+		if diff := cmp.Diff(content, pattern.Pattern); diff != "" {
+			t.Fatalf("Pattern %q mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestSyncPatternsReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	server := newSyncTestServer(t)
+	server.InjectFault(gofabrictest.Fault{
+		Method:     http.MethodPost,
+		Path:       "/patterns/analyze",
+		StatusCode: http.StatusInternalServerError,
+	})
+
+	report, err := server.Client().SyncPatterns(
+		context.Background(),
+		testPatternsTree,
+		gofabric.SyncOptions{Concurrency: 1},
+	)
+	if err != nil {
+		t.Fatalf("Failed to sync patterns: %v", err)
+	}
+
+	var entityErr *gofabric.EntityError
+	if !errors.As(report.Err(), &entityErr) {
+		t.Fatalf("Expected *EntityError, got: %v", report.Err())
+	}
+
+	for _, result := range report.Results {
+		if (result.Err != nil) != (result.Name == "analyze") {
+			t.Fatalf("Unexpected result for %q: %v", result.Name, result.Err)
+		}
+	}
+
+	if _, ok := server.Pattern("translate"); !ok {
+		t.Fatal("Expected the other patterns to be synced")
+	}
+}
+
+func TestPullPatterns(t *testing.T) {
+	t.Parallel()
+
+	server := newSyncTestServer(t)
+	dir := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "stale"), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "stale", "system.md"), []byte("Stale"), 0o644); err != nil {
+		t.Fatalf("Failed to write pattern: %v", err)
+	}
+
+	report, err := server.Client().PullPatterns(context.Background(), dir, gofabric.SyncOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("Failed to pull patterns: %v", err)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Fatalf("Failed to apply pull: %v", err)
+	}
+
+	want := map[string]gofabric.SyncAction{
+		"obsolete":  gofabric.SyncActionCreate,
+		"stale":     gofabric.SyncActionDelete,
+		"summarize": gofabric.SyncActionCreate,
+		"translate": gofabric.SyncActionCreate,
+	}
+	if diff := cmp.Diff(want, syncActions(report)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	report, err = server.Client().SyncPatterns(context.Background(), os.DirFS(dir), gofabric.SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed to sync patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]gofabric.SyncResult(nil), report.Changed(), cmpopts.EquateEmpty()); diff != "" {
+		t.Fatalf("Expected pulled patterns to be in sync (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,80 @@
+package gofabric
+
+import (
+	"regexp"
+	"strings"
+)
+
+const inputVariableName = "input"
+
+// templateTokenPattern matches the {{...}} placeholders of a pattern, like Fabric does.
+var templateTokenPattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// templateVariableName returns the name of the variable referenced by the content of a placeholder,
+// and false if the placeholder is a plugin or extension call that Fabric resolves itself.
+func templateVariableName(token string) (string, bool) {
+	name := strings.TrimSpace(token)
+	if strings.HasPrefix(name, "plugin:") || strings.HasPrefix(name, "ext:") {
+		return "", false
+	}
+
+	return name, true
+}
+
+// Variables returns the names of the variables referenced by the pattern's {{variable}} placeholders,
+// in order of first appearance.
+//
+// The {{input}} placeholder, which Fabric substitutes with the user input, and the {{plugin:...}} and
+// {{ext:...}} calls are not included.
+func (p *Pattern) Variables() []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, match := range templateTokenPattern.FindAllStringSubmatch(p.Pattern, -1) {
+		name, ok := templateVariableName(match[1])
+		if !ok || name == inputVariableName || seen[name] {
+			continue
+		}
+
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Render returns the pattern with its {{variable}} placeholders substituted with the values of vars.
+// Placeholders without a value, including {{input}} unless vars holds it, are left untouched.
+//
+// To have Fabric substitute the variables server-side instead, set PromptRequest.Variables.
+func (p *Pattern) Render(vars map[string]string) string {
+	return templateTokenPattern.ReplaceAllStringFunc(p.Pattern, func(token string) string {
+		name, ok := templateVariableName(token[2 : len(token)-2])
+		if !ok {
+			return token
+		}
+
+		if value, ok := vars[name]; ok {
+			return value
+		}
+
+		return token
+	})
+}
+
+// RenderStrict is like Render, but returns a *MissingVariablesError if vars has no value for one of
+// the pattern's Variables.
+func (p *Pattern) RenderStrict(vars map[string]string) (string, error) {
+	var missing []string
+	for _, name := range p.Variables() {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return "", &MissingVariablesError{Pattern: p.Name, Names: missing}
+	}
+
+	return p.Render(vars), nil
+}
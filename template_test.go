@@ -0,0 +1,97 @@
+package gofabric_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+var testTemplatePattern = gofabric.Pattern{
+	Name: "translate",
+	Pattern: "Translate the input to {{lang_code}} in a {{ tone }} tone.\n" +
+		"Keep {{lang_code}} idioms. {{plugin:datetime:now}}\n\n{{input}}",
+}
+
+func TestPatternVariables(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"lang_code", "tone"}
+	if diff := cmp.Diff(want, testTemplatePattern.Variables()); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	pattern := gofabric.Pattern{Name: "summarize", Pattern: "Summarize {{input}}"}
+	if variables := pattern.Variables(); len(variables) != 0 {
+		t.Fatalf("Expected no variables, got: %v", variables)
+	}
+}
+
+func TestPatternRender(t *testing.T) {
+	t.Parallel()
+
+	got := testTemplatePattern.Render(map[string]string{"lang_code": "fr"})
+	want := "Translate the input to fr in a {{ tone }} tone.\n" +
+		"Keep fr idioms. {{plugin:datetime:now}}\n\n{{input}}"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPatternRenderStrict(t *testing.T) {
+	t.Parallel()
+
+	_, err := testTemplatePattern.RenderStrict(map[string]string{"tone": "formal"})
+	if !errors.Is(err, gofabric.ErrMissingVariables) {
+		t.Fatalf("Expected ErrMissingVariables, got: %v", err)
+	}
+
+	var missingErr *gofabric.MissingVariablesError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("Expected *MissingVariablesError, got: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"lang_code"}, missingErr.Names); diff != "" {
+		t.Fatalf("Names mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err := testTemplatePattern.RenderStrict(map[string]string{
+		"lang_code": "fr",
+		"tone":      "formal",
+		"input":     "Hello",
+	})
+	if err != nil {
+		t.Fatalf("Failed to render pattern: %v", err)
+	}
+
+	want := "Translate the input to fr in a formal tone.\nKeep fr idioms. {{plugin:datetime:now}}\n\nHello"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithVariables(t *testing.T) {
+	t.Parallel()
+
+	chatRequest, err := gofabric.NewChatRequest(
+		gofabric.WithPattern("translate"),
+		gofabric.WithVariables(map[string]string{"lang_code": "fr"}),
+		gofabric.WithVariable("tone", "formal"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to build chat request: %v", err)
+	}
+
+	data, err := json.Marshal(chatRequest.Prompts[0])
+	if err != nil {
+		t.Fatalf("Failed to encode prompt: %v", err)
+	}
+
+	want := `{"userInput":"","vendor":"","model":"","contextName":"","patternName":"translate",` +
+		`"strategyName":"","sessionName":"","variables":{"lang_code":"fr","tone":"formal"}}`
+	if diff := cmp.Diff(want, string(data)); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -1,5 +1,10 @@
 package gofabric
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Entity is a type constraint for generic functions that operate on Pattern, Context, or Session types.
 type Entity interface {
 	Pattern | Context | Session
@@ -13,6 +18,15 @@ const (
 	EntityTypeSession EntityType = "session"
 )
 
+type MessageRole string
+
+const (
+	MessageRoleAssistant MessageRole = "assistant"
+	MessageRoleMeta      MessageRole = "meta"
+	MessageRoleSystem    MessageRole = "system"
+	MessageRoleUser      MessageRole = "user"
+)
+
 type StreamResponseType string
 
 const (
@@ -27,15 +41,18 @@ type AvailableModels struct {
 	Vendors map[string][]string `json:"vendors"` // Vendors maps vendor names to their models.
 }
 
+// ChatOptions contains the options of a chat. Unset options are omitted from the request so that
+// the server defaults apply; use the ChatRequestOption functions or take the address of a value to
+// set them explicitly, including to zero.
 type ChatOptions struct {
-	Model              string  `json:"model"`              // Model is the name of the model to use for the chat.
-	Temperature        float64 `json:"temperature"`        // Temperature controls the randomness of the model's responses.
-	TopP               float64 `json:"topP"`               // TopP is the nucleus sampling parameter for controlling diversity.
-	PresencePenalty    float64 `json:"presencePenalty"`    // PresencePenalty discourages repetition of tokens already present in the conversation.
-	FrequencyPenalty   float64 `json:"frequencyPenalty"`   // FrequencyPenalty discourages repetition of tokens based on their frequency in the conversation.
-	Raw                bool    `json:"raw"`                // Raw indicates whether to return raw model output without processing.
-	Seed               int     `json:"seed"`               // Seed is used for random number generation to ensure reproducibility.
-	ModelContextLength int     `json:"modelContextLength"` // ModelContextLength is the maximum context length for the model.
+	Model              string   `json:"model,omitempty"`              // Model is the name of the model to use for the chat.
+	Temperature        *float64 `json:"temperature,omitempty"`        // Temperature controls the randomness of the model's responses.
+	TopP               *float64 `json:"topP,omitempty"`               // TopP is the nucleus sampling parameter for controlling diversity.
+	PresencePenalty    *float64 `json:"presencePenalty,omitempty"`    // PresencePenalty discourages repetition of tokens already present in the conversation.
+	FrequencyPenalty   *float64 `json:"frequencyPenalty,omitempty"`   // FrequencyPenalty discourages repetition of tokens based on their frequency in the conversation.
+	Raw                bool     `json:"raw,omitempty"`                // Raw indicates whether to return raw model output without processing.
+	Seed               *int     `json:"seed,omitempty"`               // Seed is used for random number generation to ensure reproducibility.
+	ModelContextLength *int     `json:"modelContextLength,omitempty"` // ModelContextLength is the maximum context length for the model.
 }
 
 type ChatRequest struct {
@@ -44,6 +61,20 @@ type ChatRequest struct {
 	ChatOptions ChatOptions     `json:"chatOptions"` // ChatOptions contains various options for the chat session.
 }
 
+// ChatResult is the result of a chat session aggregated from its stream by ChatComplete.
+type ChatResult struct {
+	Content          string        // Content is the concatenation of all the content received.
+	Segments         []ChatSegment // Segments is the content split into consecutive runs of the same format.
+	TimeToFirstToken time.Duration // TimeToFirstToken is the time elapsed until the first content was received.
+	Duration         time.Duration // Duration is the time elapsed until the stream completed.
+}
+
+// ChatSegment is a consecutive run of streamed content sharing the same format.
+type ChatSegment struct {
+	Format  string `json:"format"`  // Format is the format of the content: "markdown", "mermaid" or "plain".
+	Content string `json:"content"` // Content is the content of the segment.
+}
+
 // Config holds configuration values for various LLM providers.
 type Config struct {
 	Anthropic  string `json:"anthropic"`  // Anthropic API key.
@@ -57,6 +88,11 @@ type Config struct {
 	OpenAI     string `json:"openai"`     // OpenAI API key.
 	OpenRouter string `json:"openrouter"` // OpenRouter API key.
 	Silicon    string `json:"silicon"`    // Silicon API key.
+
+	// Extra holds the fields of the config that are not known to this package, such as the keys of
+	// vendors added to Fabric after its release, so that they are preserved by GetConfig and
+	// UpdateConfig. Use Get, Set and Providers to access them as strings.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 // Context represents a named context file with its content.
@@ -65,6 +101,32 @@ type Context struct {
 	Content string `json:"content"` // Content is the text or data stored in the context.
 }
 
+// Message represents a single message of a chat session.
+type Message struct {
+	Role    MessageRole `json:"role"`    // Role is the sender's role (e.g., user, assistant).
+	Content string      `json:"content"` // Content is the message text.
+}
+
+// AssistantMessage returns a message with the assistant role.
+func AssistantMessage(content string) Message {
+	return Message{Role: MessageRoleAssistant, Content: content}
+}
+
+// MetaMessage returns a message with the meta role.
+func MetaMessage(content string) Message {
+	return Message{Role: MessageRoleMeta, Content: content}
+}
+
+// SystemMessage returns a message with the system role.
+func SystemMessage(content string) Message {
+	return Message{Role: MessageRoleSystem, Content: content}
+}
+
+// UserMessage returns a message with the user role.
+func UserMessage(content string) Message {
+	return Message{Role: MessageRoleUser, Content: content}
+}
+
 // Pattern represents a reusable prompt pattern with a name, description, and pattern string.
 type Pattern struct {
 	Name        string `json:"name"`        // Name of the pattern.
@@ -79,15 +141,17 @@ type PromptRequest struct {
 	ContextName  string `json:"contextName"`  // ContextName is the name of the context to use.
 	PatternName  string `json:"patternName"`  // PatternName is the name of the pattern to use.
 	StrategyName string `json:"strategyName"` // StrategyName is the name of the strategy to use.
+	SessionName  string `json:"sessionName"`  // SessionName is the name of the session the exchange is recorded in.
+
+	// Variables holds the values substituted by the server for the {{variable}} placeholders of the
+	// pattern.
+	Variables map[string]string `json:"variables,omitempty"`
 }
 
 // Session represents a chat session with a name and a list of messages.
 type Session struct {
-	Name     string `json:"name"` // Name of the session.
-	Messages []struct {
-		Role    string `json:"role"`    // Role is the sender's role (e.g., user, assistant).
-		Content string `json:"content"` // Content is the message text.
-	} `json:"messages"`
+	Name     string    `json:"name"`     // Name of the session.
+	Messages []Message `json:"messages"` // Messages is the conversation, in order.
 }
 
 // Strategy represents a named strategy with a description and associated pattern.
@@ -102,4 +166,8 @@ type StreamResponse struct {
 	Type    string `json:"type"`    // "content", "error", "complete"
 	Format  string `json:"format"`  // "markdown", "mermaid", "plain"
 	Content string `json:"content"` // The actual content
+
+	// Err is the error that ended the stream for responses of type "error" generated by the client,
+	// e.g. a *StreamInterruptedError. It is nil for responses sent by the server.
+	Err error `json:"-"`
 }
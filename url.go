@@ -0,0 +1,137 @@
+package gofabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// unixSocketScheme is the scheme of the URLs of Fabric API servers listening on a unix-domain socket.
+const unixSocketScheme = "unix"
+
+// New creates a new Client instance like NewClient, but returns an error if the host is not a valid
+// URL of a Fabric API server.
+func New(host string, opts ...Option) (*Client, error) {
+	baseURL, err := url.Parse(host)
+	if err != nil {
+		client := newClient(nil, opts)
+		client.configErr = fmt.Errorf("invalid host %q: %w", host, err)
+
+		return client, client.configErr
+	}
+
+	return NewFromURL(baseURL, opts...)
+}
+
+// NewFromURL creates a new Client instance like New, with the URL of the Fabric API server already
+// parsed. The URL is copied, so modifying it afterward does not affect the client.
+func NewFromURL(baseURL *url.URL, opts ...Option) (*Client, error) {
+	u := *baseURL
+	client := newClient(&u, opts)
+
+	if client.tlsConfig != nil {
+		httpClient, err := tlsHTTPClient(client.httpClient, client.tlsConfig)
+		if err != nil {
+			client.configErr = err
+
+			return client, err
+		}
+
+		client.httpClient = httpClient
+	}
+
+	requestURL, err := client.configureBaseURL()
+	if err != nil {
+		client.configErr = fmt.Errorf("invalid host %q: %w", u.String(), err)
+
+		return client, client.configErr
+	}
+
+	client.requestURL = requestURL
+
+	return client, nil
+}
+
+func newClient(baseURL *url.URL, opts []Option) *Client {
+	client := &Client{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout: defaultHTTPClientTimeout,
+		},
+		logger: slog.New(slog.DiscardHandler),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	client.doer = client.buildDoer()
+
+	return client
+}
+
+// configureBaseURL validates the base URL and returns the URL requests are sent to. For a unix-domain
+// socket, it also configures the HTTP client to connect to the socket.
+func (c *Client) configureBaseURL() (*url.URL, error) {
+	switch c.baseURL.Scheme {
+	case "http", "https":
+		if c.baseURL.Host == "" {
+			return nil, errors.New("missing host name")
+		}
+
+		return c.baseURL, nil
+	case unixSocketScheme:
+		if c.baseURL.Host != "" || c.baseURL.Path == "" {
+			return nil, errors.New("expected unix:///path/to/socket")
+		}
+
+		httpClient, err := unixSocketHTTPClient(c.httpClient, c.baseURL.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		c.httpClient = httpClient
+
+		// The host name is only used in the Host header of the requests.
+		return &url.URL{Scheme: "http", Host: "localhost"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, expected http, https or unix", c.baseURL.Scheme)
+	}
+}
+
+// BaseURL returns the URL of the Fabric API server, or nil if it is invalid. Modifying the returned URL
+// does not affect the client.
+func (c *Client) BaseURL() *url.URL {
+	if c.baseURL == nil {
+		return nil
+	}
+
+	u := *c.baseURL
+
+	return &u
+}
+
+// unixSocketHTTPClient returns a copy of httpClient connecting to the unix-domain socket at
+// socketPath. The transport of httpClient must be nil or an *http.Transport.
+func unixSocketHTTPClient(httpClient *http.Client, socketPath string) (*http.Client, error) {
+	transport, err := cloneTransport(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to a unix-domain socket: %w", err)
+	}
+
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+
+	client := *httpClient
+	client.Transport = transport
+
+	return &client, nil
+}
@@ -0,0 +1,185 @@
+package gofabric_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sherif-fanous/gofabric"
+)
+
+// namesHandler serves the pattern names at /patterns/names under prefix, recording the paths
+// requested.
+func namesHandler(prefix string, paths *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.EscapedPath())
+
+		if r.URL.Path != prefix+"/patterns/names" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode([]string{"summarize"})
+	})
+}
+
+func TestNewInvalidHost(t *testing.T) {
+	t.Parallel()
+
+	for _, host := range []string{
+		"://missing-scheme",
+		"localhost:8080",
+		"ftp://example.com",
+		"http://",
+		"unix://host/run/fabric.sock",
+		"unix://",
+	} {
+		client, err := gofabric.New(host)
+		if err == nil {
+			t.Errorf("Expected an error for host %q", host)
+
+			continue
+		}
+
+		// The client returned along with the error reports it on every request.
+		if _, reqErr := client.ListPatterns(context.Background()); !errors.Is(reqErr, err) {
+			t.Errorf("Expected the requests to fail with %v, got %v", err, reqErr)
+		}
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	server := httptest.NewServer(namesHandler("/fabric/api", &paths))
+	defer server.Close()
+
+	for _, host := range []string{server.URL + "/fabric/api", server.URL + "/fabric/api/"} {
+		client, err := gofabric.New(host)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		names, err := client.ListPatterns(context.Background())
+		if err != nil {
+			t.Fatalf("Failed to list patterns: %v", err)
+		}
+
+		if diff := cmp.Diff([]string{"summarize"}, names); diff != "" {
+			t.Fatalf("Mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	client, err := gofabric.New(server.URL + "/fabric/api")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, err = client.PatternExists(context.Background(), "a b?")
+	if !errors.Is(err, gofabric.ErrNotFound) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+
+	want := []string{
+		"/fabric/api/patterns/names",
+		"/fabric/api/patterns/names",
+		"/fabric/api/patterns/exists/a%20b%3F",
+	}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	// Socket paths are limited to about 100 bytes, which t.TempDir may exceed.
+	dir, err := os.MkdirTemp("", "gofabric")
+	if err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	socketPath := filepath.Join(dir, "fabric.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("Unix-domain sockets are not supported: %v", err)
+	}
+
+	var paths []string
+	server := httptest.NewUnstartedServer(namesHandler("", &paths))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := gofabric.New("unix://" + socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	names, err := client.ListPatterns(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list patterns: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"summarize"}, names); diff != "" {
+		t.Fatalf("Mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := client.BaseURL().String(); got != "unix://"+socketPath {
+		t.Fatalf("Expected the base URL to be the socket URL, got %s", got)
+	}
+}
+
+func TestUnixSocketCustomTransport(t *testing.T) {
+	t.Parallel()
+
+	httpClient := &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("unexpected request")
+	})}
+
+	_, err := gofabric.New("unix:///run/fabric.sock", gofabric.WithHTTPClient(httpClient))
+	if err == nil || !strings.Contains(err.Error(), "unix-domain socket") {
+		t.Fatalf("Expected an error for the custom transport, got %v", err)
+	}
+}
+
+func TestBaseURL(t *testing.T) {
+	t.Parallel()
+
+	baseURL, err := url.Parse("https://gateway.internal/fabric/api")
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+
+	client, err := gofabric.NewFromURL(baseURL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// Neither the URL passed to the constructor nor the one returned are shared with the client.
+	baseURL.Path = "/other"
+	client.BaseURL().Path = "/other"
+
+	if got := client.BaseURL().String(); got != "https://gateway.internal/fabric/api" {
+		t.Fatalf("Expected the base URL to be unchanged, got %s", got)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}